@@ -0,0 +1,6 @@
+package main
+
+import "os"
+
+// Root is the Linux equivalent of an elevated token.
+func linuxIsAdmin(c *collector) bool { return os.Geteuid() == 0 }
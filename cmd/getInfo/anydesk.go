@@ -1,68 +1,23 @@
 package main
 
 import (
-	"bytes"
-	"context"
-	"errors"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strings"
-	"syscall"
 	"time"
 )
 
-func findAnyDeskExe() string {
-	if p, err := exec.LookPath("anydesk.exe"); err == nil {
-		return p
-	}
-	if p, err := exec.LookPath("anydesk"); err == nil {
-		return p
-	}
-	cands := []string{
-		filepath.Join(os.Getenv("ProgramFiles"), "AnyDesk", "AnyDesk.exe"),
-		filepath.Join(os.Getenv("ProgramFiles(x86)"), "AnyDesk", "AnyDesk.exe"),
-	}
-	for _, p := range cands {
-		if _, err := os.Stat(p); err == nil {
-			return p
-		}
-	}
-	// Lightweight scan first level (helps branded installers)
-	for _, base := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")} {
-		entries, err := os.ReadDir(base)
-		if err != nil {
-			continue
-		}
-		for _, e := range entries {
-			if !e.IsDir() {
-				continue
-			}
-			if strings.Contains(strings.ToLower(e.Name()), "anydesk") {
-				dir := filepath.Join(base, e.Name())
-				items, _ := os.ReadDir(dir)
-				for _, it := range items {
-					n := strings.ToLower(it.Name())
-					if !it.IsDir() && strings.HasSuffix(n, ".exe") && strings.Contains(n, "anydesk") {
-						return filepath.Join(dir, it.Name())
-					}
-				}
-			}
-		}
-	}
-	return ""
-}
+// anydeskExe is findAnyDeskExe, or the path the recorded run found.
+func (c *collector) anydeskExe() string { return c.fact("anydesk_exe", findAnyDeskExe) }
 
 func anydeskGetID(c *collector) string {
-	exe := findAnyDeskExe()
+	exe := c.anydeskExe()
 	if exe == "" {
-		c.addErr("anydesk_id", errors.New("anydesk nao encontrado"), "")
+		c.addErr("anydesk_id", msgError("anydesk.not_found"), "")
 		return ""
 	}
-	out, err := runCmdTimeout(6, exe, "--get-id")
+	out, err := c.runCmdTimeout(6, exe, "--get-id")
 	if err != nil || strings.TrimSpace(out) == "" {
-		c.addErr("anydesk_id", errors.New("falha ao obter id"), "")
+		c.addErr("anydesk_id", msgError("anydesk.no_id"), "")
 		return ""
 	}
 	re := regexp.MustCompile(`\b\d{9,10}\b`)
@@ -74,39 +29,17 @@ func anydeskGetID(c *collector) string {
 
 func anydeskSetPassword(c *collector, pwd string) {
 	if strings.TrimSpace(pwd) == "" {
-		c.addErr("anydesk_setpwd", errors.New("senha ausente"), "")
-		return
-	}
-	if !isAdmin() {
-		// With manifest this should not happen; log just in case.
-		c.addErr("anydesk_setpwd", errors.New("requer administrador"), "")
+		c.addErr("anydesk_setpwd", msgError("anydesk.no_password"), "")
 		return
 	}
-	exe := findAnyDeskExe()
+	exe := c.anydeskExe()
 	if exe == "" {
-		c.addErr("anydesk_setpwd", errors.New("anydesk nao encontrado"), "")
-		return
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, exe, "--set-password")
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		c.addErr("anydesk_setpwd", err, "stdin")
-		return
-	}
-	var out bytes.Buffer
-	cmd.Stdout, cmd.Stderr = &out, &out
-
-	if err := cmd.Start(); err != nil {
-		c.addErr("anydesk_setpwd", err, "start")
+		c.addErr("anydesk_setpwd", msgError("anydesk.not_found"), "")
 		return
 	}
-	_, _ = stdin.Write([]byte(pwd + "\n"))
-	_ = stdin.Close()
-	if err := cmd.Wait(); err != nil {
+	// Password goes through stdin so it never shows up in the process list
+	// (and is never written to a recording).
+	if _, err := c.run(Command{Name: exe, Args: []string{"--set-password"}, Stdin: pwd + "\n", Timeout: 10 * time.Second}); err != nil {
 		c.addErr("anydesk_setpwd", err, "")
 	}
 }
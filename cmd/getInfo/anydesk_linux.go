@@ -0,0 +1,18 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+)
+
+func findAnyDeskExe() string {
+	if p, err := exec.LookPath("anydesk"); err == nil {
+		return p
+	}
+	for _, p := range []string{"/usr/bin/anydesk", "/opt/anydesk/anydesk", "/usr/local/bin/anydesk"} {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func findAnyDeskExe() string {
+	if p, err := exec.LookPath("anydesk.exe"); err == nil {
+		return p
+	}
+	if p, err := exec.LookPath("anydesk"); err == nil {
+		return p
+	}
+	cands := []string{
+		filepath.Join(os.Getenv("ProgramFiles"), "AnyDesk", "AnyDesk.exe"),
+		filepath.Join(os.Getenv("ProgramFiles(x86)"), "AnyDesk", "AnyDesk.exe"),
+	}
+	for _, p := range cands {
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+	}
+	// Lightweight scan first level (helps branded installers)
+	for _, base := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")} {
+		entries, err := os.ReadDir(base)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			if strings.Contains(strings.ToLower(e.Name()), "anydesk") {
+				dir := filepath.Join(base, e.Name())
+				items, _ := os.ReadDir(dir)
+				for _, it := range items {
+					n := strings.ToLower(it.Name())
+					if !it.IsDir() && strings.HasSuffix(n, ".exe") && strings.Contains(n, "anydesk") {
+						return filepath.Join(dir, it.Name())
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name    string
+	summary string // catalog key
+	run     func(args []string, stdin *bufio.Reader) int
+}
+
+// Filled in init: PrintUsageAndExit lists commands and collect calls it.
+var commands []command
+
+func init() {
+	commands = []command{
+		{"collect", "cmd.collect", cmdCollect},
+		{"show", "cmd.show", cmdShow},
+		{"export", "cmd.export", cmdExport},
+		{"doctor", "cmd.doctor", cmdDoctor},
+		{"version", "cmd.version", cmdVersion},
+	}
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "%s %s %s %s\n", tr("usage.prefix"), exeName(), name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// flag already printed the problem; -h is not an error.
+func flagExit(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	return 2
+}
+
+func cmdCollect(args []string, stdin *bufio.Reader) int {
+	fs := newFlagSet("collect", tr("collect.args"))
+	asset := fs.String("asset", "", tr("collect.asset"))
+	name := fs.String("name", "", tr("collect.name"))
+	loc := fs.String("location", "", tr("collect.location"))
+	xlsxOut := fs.Bool("xlsx", false, tr("collect.xlsx"))
+	jsonOut := fs.Bool("json", false, tr("collect.json"))
+	ndjsonOut := fs.Bool("ndjson", false, tr("collect.ndjson"))
+	if err := fs.Parse(args); err != nil {
+		return flagExit(err)
+	}
+	req := collectRequest{in: operatorInput{patr: *asset, nome: *name, local: *loc}, xlsx: *xlsxOut, json: *jsonOut, ndjson: *ndjsonOut}
+	if rest := fs.Args(); len(rest) > 0 {
+		if len(rest) < 3 {
+			fs.Usage()
+			return 2
+		}
+		req.in = operatorInput{patr: rest[0], nome: rest[1], local: strings.TrimSpace(strings.Join(rest[2:], " "))}
+	}
+	switch n := countSet(req.in.patr, req.in.nome, req.in.local); n {
+	case 3:
+		req.haveInput = true
+	case 0:
+		// prompt
+	default:
+		fmt.Fprintln(os.Stderr, tr("collect.together"))
+		return 2
+	}
+	return runCollect(req, stdin)
+}
+
+func countSet(vs ...string) int {
+	n := 0
+	for _, v := range vs {
+		if strings.TrimSpace(v) != "" {
+			n++
+		}
+	}
+	return n
+}
+
+func cmdShow(args []string, _ *bufio.Reader) int {
+	fs := newFlagSet("show", "[--format text|json|csv]")
+	format := fs.String("format", "text", tr("show.format"))
+	if err := fs.Parse(args); err != nil {
+		return flagExit(err)
+	}
+	if *format != "text" && *format != "json" && *format != "csv" {
+		fmt.Fprintln(os.Stderr, tr("cli.bad_format", *format))
+		return 2
+	}
+	cfg, err := peekConfig(exeDir())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("err.config", err))
+		return 1
+	}
+	c, finish, err := newRunCollector(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("err.fixtures", err))
+		return 1
+	}
+	vals, _ := gather(c, cfg, cfg.collectors(true), func() (operatorInput, error) { return operatorInput{}, nil })
+	_ = finish()
+	rec := newRecord(vals, c.runID)
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(rec)
+	case "csv":
+		_, err = os.Stdout.Write(cfg.csvDialect().encode(Headers(), [][]string{Row(cfg.Vocabulary.render(rec.Values()))}))
+	default:
+		printSummary(os.Stdout, cfg.Vocabulary.render(rec.Values()))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("err.generic", err))
+		return 1
+	}
+	// Non-fatal problems go to stderr so stdout stays machine-readable.
+	for _, e := range c.errors() {
+		fmt.Fprintln(os.Stderr, e)
+	}
+	return 0
+}
+
+func cmdVersion(args []string, _ *bufio.Reader) int {
+	fmt.Println(versionString())
+	return 0
+}
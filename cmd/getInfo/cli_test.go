@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The first positional word of the legacy form is a value, not a command.
+func TestFindCommand(t *testing.T) {
+	for _, name := range []string{"collect", "show", "export", "doctor", "version"} {
+		if findCommand(name) == nil {
+			t.Errorf("%s not found", name)
+		}
+	}
+	if findCommand("PAT-0042") != nil {
+		t.Error("asset tag taken as a command")
+	}
+}
+
+// export reads what the collect run wrote (BOM, sep= line) and pads short
+// rows from older builds.
+func TestExportRows(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CsvName)
+	os.WriteFile(path, []byte("\xEF\xBB\xBFsep=;\r\nSN;Host;Obs\r\n7XK3Q93;PC-01;\"a;b\"\r\nSN2;PC-02\r\n"), 0644)
+	header, rows, err := readInventoryCSV(path, defaultDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := exportRows(&b, "ndjson", header, rows, defaultDialect); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"SN":"7XK3Q93","Host":"PC-01","Obs":"a;b"}` + "\n" + `{"SN":"SN2","Host":"PC-02","Obs":""}` + "\n"
+	if b.String() != want {
+		t.Errorf("ndjson:\n%s", b.String())
+	}
+	b.Reset()
+	if err := exportRows(&b, "tsv", header, rows, defaultDialect); err != nil {
+		t.Fatal(err)
+	}
+	if want := "SN\tHost\tObs\r\n7XK3Q93\tPC-01\ta;b\r\nSN2\tPC-02\r\n"; b.String() != want {
+		t.Errorf("tsv:\n%q", b.String())
+	}
+}
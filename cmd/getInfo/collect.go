@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+type collectOptions struct {
+	workers      int
+	perCollector time.Duration
+	total        time.Duration
+}
+
+type collectResult struct {
+	idx  int
+	vals Values
+}
+
+// collectAll runs the given collectors on a bounded worker pool. Each one
+// gets opts.perCollector; whatever has not finished by opts.total is logged
+// and its columns stay empty. Results are merged in registry order so the
+// outcome does not depend on scheduling.
+func collectAll(c *collector, cols []Collector, opts collectOptions) Values {
+	var todo []int
+	admin, adminChecked := false, false
+	for i, col := range cols {
+		if !supportsPlatform(col, c.platform().goos) {
+			continue
+		}
+		if col.NeedsAdmin() {
+			if !adminChecked {
+				admin, adminChecked = isAdmin(c), true
+			}
+			if !admin {
+				c.addErr(col.Name(), msgError("collect.needs_admin"), "")
+				continue
+			}
+		}
+		todo = append(todo, i)
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, opts.total)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan collectResult, len(todo))
+	workers := opts.workers
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				if v, ok := runOne(c, ctx, cols[i], opts.perCollector); ok {
+					results <- collectResult{idx: i, vals: v}
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for _, i := range todo {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	got := make(map[int]Values, len(todo))
+wait:
+	for len(got) < len(todo) {
+		select {
+		case r := <-results:
+			got[r.idx] = r.vals
+		case <-ctx.Done():
+			break wait
+		}
+	}
+
+	vals := Values{}
+	for _, i := range todo {
+		v, ok := got[i]
+		if !ok {
+			c.addErr(cols[i].Name(), msgError("collect.deadline"), opts.total.String())
+			continue
+		}
+		for k, s := range v {
+			vals[k] = s
+		}
+	}
+	return vals
+}
+
+// runOne runs a single collector with its own timeout. On timeout the
+// collector's commands are killed via ctx and its result is dropped; ok is
+// false only when the overall deadline (parent) cut it short.
+//
+// The collector logs into its own errLog, handed back with its values; a
+// collector that overran keeps running on a log nobody reads, so nothing is
+// added to the run's log after collectAll returns and writeErrors runs.
+func runOne(c *collector, parent context.Context, col Collector, timeout time.Duration) (v Values, ok bool) {
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	type outcome struct {
+		vals Values
+		errs []string
+	}
+	done := make(chan outcome, 1)
+	cc := c.withContext(ctx)
+	cc.log = &errLog{}
+	go func() {
+		v := col.Collect(cc)
+		done <- outcome{v, cc.errors()}
+	}()
+	select {
+	case o := <-done:
+		c.log.append(o.errs)
+		return o.vals, true
+	case <-ctx.Done():
+		if parent.Err() != nil {
+			return nil, false
+		}
+		c.addErr(col.Name(), msgError("collect.timeout"), timeout.String())
+		return nil, true
+	}
+}
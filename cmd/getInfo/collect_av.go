@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// AVProduct is one antivirus registered with Windows Security Center
+// (root/SecurityCenter2 AntiVirusProduct).
+type AVProduct struct {
+	Name         string `json:"name"`
+	State        string `json:"state"` // avOn, avOff, avSnoozed, avExpired, avUnknown
+	UpToDate     bool   `json:"upToDate"`
+	ProductState uint32 `json:"productState"`
+	Path         string `json:"path,omitempty"`
+}
+
+// DefenderStatus is Microsoft Defender as Get-MpComputerStatus sees it; it
+// is reported even when another product has taken over (passive mode).
+type DefenderStatus struct {
+	State            string `json:"state"` // avOn, avOff, avPassive
+	RealTime         bool   `json:"realTime"`
+	Mode             string `json:"mode,omitempty"` // AMRunningMode: "Normal", "Passive Mode"...
+	SignatureAgeDays int    `json:"signatureAgeDays"`
+	UpToDate         bool   `json:"upToDate"`
+}
+
+// Canonical states, stored in the JSON record and written as is in the
+// columns: data does not follow the UI language.
+const (
+	avOn      = "on"
+	avOff     = "off"
+	avSnoozed = "snoozed"
+	avExpired = "expired"
+	avPassive = "passive"
+	avUnknown = "unknown" // productState scanner value not documented anywhere
+)
+
+// defenderMaxSignatureAgeDays: older Defender definitions count as out of date.
+const defenderMaxSignatureAgeDays = 7
+
+// Keys carrying the JSON lists from the collector to newRecord; not columns.
+const (
+	avProductsKey = "av_products"
+	defenderKey   = "defender_status"
+)
+
+// decodeProductState reads the undocumented but stable productState DWORD:
+// bits 12-15 are the scanner state (0 off, 1 on, 2 snoozed, 3 expired) and
+// bits 4-7 the definitions (0 up to date, 1 out of date). The top byte is
+// the provider (antivirus, antispyware...) and is not needed here.
+// Examples: 0x061100 (397568) on and up to date, 0x060110 (393488) off and
+// out of date. Other scanner values are reported as unknown, not off.
+func decodeProductState(v uint32) (state string, upToDate bool) {
+	switch (v >> 12) & 0xF {
+	case 0x0:
+		state = avOff
+	case 0x1:
+		state = avOn
+	case 0x2:
+		state = avSnoozed
+	case 0x3:
+		state = avExpired
+	default:
+		state = avUnknown
+	}
+	return state, (v>>4)&0xF == 0
+}
+
+// defenderState folds the Get-MpComputerStatus flags into one state.
+func defenderState(service, antivirus, realTime bool, mode string) string {
+	switch {
+	case strings.Contains(strings.ToLower(mode), "passive"):
+		return avPassive
+	case service && antivirus && realTime:
+		return avOn
+	default:
+		return avOff
+	}
+}
+
+// avLabel is "on, up to date".
+func avLabel(state string, upToDate bool) string {
+	if upToDate {
+		return state + ", up to date"
+	}
+	return state + ", out of date"
+}
+
+// avSummary is the Antivirus column: "Name (on, up to date); Name (off, ...)".
+func avSummary(products []AVProduct) string {
+	if len(products) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(products))
+	for i, p := range products {
+		parts[i] = p.Name + " (" + avLabel(p.State, p.UpToDate) + ")"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// bitdefenderEdition picks the product among the Bitdefender uninstall
+// entries (the agent, VPN and updaters are installed next to it), falling
+// back to what Security Center reports.
+func bitdefenderEdition(installed []string, products []AVProduct) string {
+	var first string
+	for _, name := range installed {
+		low := strings.ToLower(name)
+		if !strings.HasPrefix(low, "bitdefender") {
+			continue
+		}
+		for _, w := range []string{"security", "antivirus", "endpoint", "gravityzone", "total", "internet", "plus", "free"} {
+			if strings.Contains(low, w) {
+				return name
+			}
+		}
+		if first == "" {
+			first = name
+		}
+	}
+	if first != "" {
+		return first
+	}
+	for _, p := range products {
+		if strings.Contains(strings.ToLower(p.Name), "bitdefender") {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// securityInfo is what getSecurity found; nil parts were not available.
+type securityInfo struct {
+	products    []AVProduct // nil: Security Center did not answer
+	defender    *DefenderStatus
+	bitdefender []string // uninstall DisplayNames starting with "Bitdefender"
+}
+
+func collectSecurity(c *collector) Values {
+	info, err := getSecurity(c)
+	if err != nil {
+		c.addErr("antivirus", err, "")
+		return Values{}
+	}
+	v := Values{}
+	if info.products != nil {
+		v[FieldAV.Key] = avSummary(info.products)
+		if b, err := json.Marshal(info.products); err == nil {
+			v[avProductsKey] = string(b)
+		}
+	} else {
+		c.addErr("antivirus", ErrNotFound, "SecurityCenter2")
+	}
+	if d := info.defender; d != nil {
+		v[FieldDefender.Key] = avLabel(d.State, d.UpToDate)
+		if b, err := json.Marshal(d); err == nil {
+			v[defenderKey] = string(b)
+		}
+	}
+	v[FieldBD.Key] = bitdefenderEdition(info.bitdefender, info.products)
+	return v
+}
@@ -0,0 +1,5 @@
+package main
+
+// Security Center and Defender are Windows-only ("antivirus" is marked so in
+// the registry).
+func linuxSecurity(c *collector) (*securityInfo, error) { return nil, ErrNotFound }
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestDecodeProductState(t *testing.T) {
+	for _, tc := range []struct {
+		v        uint32
+		state    string
+		upToDate bool
+	}{
+		{0x061100, avOn, true},       // 397568: Windows Defender, active
+		{0x060110, avOff, false},     // 393488: Windows Defender, disabled and stale
+		{0x041000, avOn, true},       // 266240: third-party antivirus
+		{0x062100, avSnoozed, true},  // 401664
+		{0x063110, avExpired, false}, // 405776
+		{0x060100, avOff, true},      // 393472
+		{0x068100, avUnknown, true},  // scanner nibble 8: undocumented
+		{0x06F110, avUnknown, false},
+	} {
+		state, upToDate := decodeProductState(tc.v)
+		if state != tc.state || upToDate != tc.upToDate {
+			t.Errorf("%#06x: %s, %v; want %s, %v", tc.v, state, upToDate, tc.state, tc.upToDate)
+		}
+	}
+}
+
+func TestBitdefenderEdition(t *testing.T) {
+	products := []AVProduct{{Name: "Bitdefender Antivirus Free"}}
+	for _, c := range []struct {
+		installed []string
+		want      string
+	}{
+		{[]string{"Bitdefender Agent", "Bitdefender Total Security"}, "Bitdefender Total Security"},
+		{[]string{"Bitdefender Agent", "Bitdefender VPN"}, "Bitdefender Agent"},
+		{nil, "Bitdefender Antivirus Free"},
+	} {
+		if got := bitdefenderEdition(c.installed, products); got != c.want {
+			t.Errorf("bitdefenderEdition(%q) = %q, want %q", c.installed, got, c.want)
+		}
+	}
+}
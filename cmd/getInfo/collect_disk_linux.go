@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Size and free space of the filesystem mounted at root.
+func statfsGiB(root string) (total int64, free int64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(root, &st); err != nil {
+		return 0, 0, err
+	}
+	bs := int64(st.Bsize)
+	return toGiB(int64(st.Blocks) * bs), toGiB(int64(st.Bavail) * bs), nil
+}
+
+// skipBlock drops the block devices that are not disks.
+func skipBlock(name string) bool {
+	for _, p := range []string{"loop", "ram", "zram", "dm-", "sr", "md", "fd"} {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// linuxReadStorage lists the disks in /sys/block and the filesystems mounted from
+// them; "/" marks the system volume.
+func linuxReadStorage(c *collector) (*storageInfo, error) {
+	root := c.fsRoot()
+	dir := filepath.Join(root, "sys", "block")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	s := &storageInfo{}
+	for _, e := range entries {
+		n := e.Name()
+		if skipBlock(n) {
+			continue
+		}
+		s.disks = append(s.disks, readBlockDisk(dir, n))
+	}
+
+	mounts, err := os.ReadFile(filepath.Join(root, "proc", "self", "mounts"))
+	if err != nil {
+		c.addErr("storage", err, "")
+	}
+	seen := map[string]bool{}
+	for _, ln := range strings.Split(string(mounts), "\n") {
+		f := strings.Fields(ln)
+		if len(f) < 3 || !strings.HasPrefix(f[0], "/dev/") {
+			continue
+		}
+		dev := f[0]
+		if p, err := filepath.EvalSymlinks(filepath.Join(root, dev)); err == nil {
+			dev = p // /dev/mapper/vg-root -> /dev/dm-0
+		}
+		dev = filepath.Base(dev)
+		disk := backingDisk(root, dev, 0)
+		if seen[dev] || disk == "" || skipBlock(disk) {
+			continue
+		}
+		seen[dev] = true
+		v := Volume{Mount: unescapeMount(f[1]), FS: f[2], Disk: disk}
+		if t, free, err := statfsGiB(filepath.Join(root, v.Mount)); err == nil {
+			v.SizeGB, v.FreeGB = t, free
+		}
+		s.volumes = append(s.volumes, v)
+	}
+	s.markSystem("/")
+	return s, nil
+}
+
+func readBlockDisk(dir, n string) PhysicalDisk {
+	read := func(p ...string) string {
+		b, err := os.ReadFile(filepath.Join(append([]string{dir, n}, p...)...))
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(b))
+	}
+	d := PhysicalDisk{Name: n, Model: read("device", "model"), Serial: read("device", "serial")}
+	if d.Serial == "" {
+		// SCSI/SATA: unit serial number VPD page, after a 4-byte header.
+		if b := read("device", "vpd_pg80"); len(b) > 4 {
+			d.Serial = strings.TrimSpace(strings.Trim(b[4:], "\x00"))
+		}
+	}
+	if sectors, err := strconv.ParseInt(read("size"), 10, 64); err == nil {
+		d.SizeGB = toGiB(sectors * 512) // always 512-byte units
+	}
+	switch read("queue", "rotational") {
+	case "0":
+		d.Media = mediaSSD
+	case "1":
+		d.Media = mediaHDD
+	}
+	path, _ := filepath.EvalSymlinks(filepath.Join(dir, n))
+	switch {
+	case strings.HasPrefix(n, "nvme"):
+		d.Bus = "NVMe"
+	case strings.HasPrefix(n, "mmcblk"):
+		d.Bus = "MMC"
+	case strings.Contains(path, "/usb"):
+		d.Bus = "USB"
+	case strings.Contains(path, "/virtio"):
+		d.Bus = "Virtio"
+	case strings.Contains(path, "/ata"):
+		d.Bus = "SATA"
+	case strings.Contains(path, "/host"):
+		d.Bus = "SCSI"
+	}
+	return d
+}
+
+// backingDisk follows a partition to its disk and a device-mapper/md device
+// (LVM, LUKS, RAID) through its slaves to the first physical disk.
+func backingDisk(root, dev string, depth int) string {
+	if depth > 8 {
+		return ""
+	}
+	whole := filepath.Join(root, "sys", "block", dev)
+	if _, err := os.Stat(whole); err == nil {
+		if slaves, _ := os.ReadDir(filepath.Join(whole, "slaves")); len(slaves) > 0 {
+			return backingDisk(root, slaves[0].Name(), depth+1)
+		}
+		return dev
+	}
+	// A partition lives under its disk: .../block/sda/sda2.
+	p, err := filepath.EvalSymlinks(filepath.Join(root, "sys", "class", "block", dev))
+	if err != nil {
+		return ""
+	}
+	return backingDisk(root, filepath.Base(filepath.Dir(p)), depth+1)
+}
+
+// unescapeMount undoes the octal escapes of /proc/mounts ("\040" = space).
+func unescapeMount(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func linuxDiskSystemGiB(c *collector) (total string, free string) {
+	t, f, err := statfsGiB(c.fsRoot())
+	if err != nil {
+		c.addErr("disk", err, c.fsRoot())
+		return "", ""
+	}
+	return strconvFormatInt(t), strconvFormatInt(f)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// Partitions resolve to their disk and LVM volumes through their slaves.
+func TestBackingDisk(t *testing.T) {
+	for dev, want := range map[string]string{
+		"nvme0n1":   "nvme0n1",
+		"nvme0n1p1": "nvme0n1",
+		"sda1":      "sda",
+		"dm-0":      "nvme0n1",
+		"sdz9":      "",
+	} {
+		if got := backingDisk(desktopRoot, dev, 0); got != want {
+			t.Errorf("backingDisk(%s) = %q, want %q", dev, got, want)
+		}
+	}
+}
+
+// The desktop boots from LVM on an NVMe disk and has a SATA HDD for data;
+// loop devices and pseudo filesystems are left out.
+func TestReadStorageLinux(t *testing.T) {
+	c := newCollector()
+	c.root = desktopRoot
+	s, err := readStorage(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []PhysicalDisk{
+		{Name: "nvme0n1", Model: "Samsung SSD 980 PRO 500GB", Serial: "S5GXNX0T123456A", Bus: "NVMe", Media: mediaSSD, SizeGB: 477, System: true},
+		{Name: "sda", Model: "WDC WD10EZEX-08W", Serial: "WD-WCC6Y1234567", Bus: "SATA", Media: mediaHDD, SizeGB: 932},
+	}
+	if !slices.Equal(s.disks, want) {
+		t.Errorf("disks = %+v", s.disks)
+	}
+	var mounts []string
+	for _, v := range s.volumes {
+		mounts = append(mounts, v.Mount+"="+v.Disk)
+	}
+	if !slices.Equal(mounts, []string{"/=nvme0n1", "/boot/efi=nvme0n1", "/mnt/dados backup=sda"}) || !s.volumes[0].System {
+		t.Errorf("volumes = %+v", s.volumes)
+	}
+	if got := getIsSSD(c); got != valueYes {
+		t.Errorf("ssd = %q", got)
+	}
+	if got := collectStorage(c)[FieldDisks.Key]; got != "NVMe SSD 477GB; SATA HDD 932GB" {
+		t.Errorf("disks column = %q", got)
+	}
+}
+
+// Without mounts there is no system disk, so SSD stays unknown.
+func TestReadStorageNoMounts(t *testing.T) {
+	c := newCollector()
+	c.root = armRoot
+	if got := getIsSSD(c); got != "" || len(c.errors()) == 0 {
+		t.Errorf("ssd = %q, errors %q", got, c.errors())
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// GPUAdapter is one video controller. The list goes to the JSON record; the
+// CSV gets the GPU_* summary columns.
+type GPUAdapter struct {
+	Name          string `json:"name"`
+	Vendor        string `json:"vendor,omitempty"`
+	PCIID         string `json:"pciId,omitempty"`  // "10de:1f82"
+	PCIBus        string `json:"pciBus,omitempty"` // Linux: "0000:01:00.0"
+	Driver        string `json:"driver,omitempty"` // Linux kernel module
+	DriverVersion string `json:"driverVersion,omitempty"`
+	DriverDate    string `json:"driverDate,omitempty"` // YYYY-MM-DD
+	VRAMMB        int64  `json:"vramMB,omitempty"`     // dedicated memory; 0 = unknown or shared
+}
+
+// gpusKey carries the JSON-encoded adapter list from the collector to
+// newRecord. It is not a column.
+const gpusKey = "gpus"
+
+// collectGPU fills GPU (yes/no), and model, VRAM and driver of the adapters,
+// the one with most dedicated memory first: that is the one that matters for
+// CAD and 3D work.
+func collectGPU(c *collector) Values {
+	gpus, err := getGPUs(c)
+	if err != nil {
+		c.addErr("gpu", err, "")
+		return Values{}
+	}
+	if len(gpus) == 0 {
+		return Values{FieldGPU.Key: valueNo}
+	}
+	sort.SliceStable(gpus, func(i, j int) bool { return gpus[i].VRAMMB > gpus[j].VRAMMB })
+	names := make([]string, len(gpus))
+	for i, g := range gpus {
+		names[i] = g.Name
+	}
+	v := Values{
+		FieldGPU.Key:       valueYes,
+		FieldGPUModel.Key:  strings.Join(names, "; "),
+		FieldGPUDriver.Key: gpus[0].driverLabel(),
+	}
+	if gpus[0].VRAMMB > 0 {
+		v[FieldGPUVRAM.Key] = vramGiB(gpus[0].VRAMMB)
+	}
+	if b, err := json.Marshal(gpus); err == nil {
+		v[gpusKey] = string(b)
+	}
+	return v
+}
+
+// vramGiB keeps the column in GiB like RAM and Disk, always with one decimal
+// so a 256 MB card does not read "0" and every row has the same format.
+// Still a plain number, so the workbook keeps it numeric.
+func vramGiB(mb int64) string {
+	return fmtGiB(max(float64(mb)/1024, 0.1))
+}
+
+// driverLabel is "nvidia 535.129.03" on Linux, "31.0.15.3623 (2023-08-02)"
+// on Windows.
+func (g GPUAdapter) driverLabel() string {
+	s := strings.TrimSpace(g.Driver + " " + g.DriverVersion)
+	if g.DriverDate != "" {
+		s = strings.TrimSpace(s + " (" + g.DriverDate + ")")
+	}
+	return s
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readGPUs lists the DRM cards (card0, card1...; connectors such as
+// card0-HDMI-A-1 are skipped). Names come from pci.ids when it is installed.
+// A machine without /sys/class/drm has no GPU.
+func readGPUs(root string) ([]GPUAdapter, error) {
+	dir := filepath.Join(root, "sys", "class", "drm")
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := loadPCIIDs(root)
+	var gpus []GPUAdapter
+	seen := map[string]bool{}
+	for _, e := range entries {
+		n := e.Name()
+		if !strings.HasPrefix(n, "card") || strings.Contains(n, "-") {
+			continue
+		}
+		dev := filepath.Join(dir, n, "device")
+		// The same PCI function can back several card nodes.
+		pci, err := filepath.EvalSymlinks(dev)
+		if err != nil || seen[pci] {
+			continue
+		}
+		seen[pci] = true
+
+		vendor, device := readHex(filepath.Join(dev, "vendor")), readHex(filepath.Join(dev, "device"))
+		g := GPUAdapter{PCIID: vendor + ":" + device, PCIBus: filepath.Base(pci)}
+		if vendor == "" || device == "" {
+			g.PCIID = ""
+		}
+		g.Vendor, g.Name = ids.lookup(vendor, device)
+		if g.Name == "" {
+			g.Name = g.PCIID
+		}
+		if g.Vendor != "" {
+			g.Name = g.Vendor + " " + g.Name
+		}
+		if drv, err := os.Readlink(filepath.Join(dev, "driver")); err == nil {
+			g.Driver = filepath.Base(drv)
+			if b, err := os.ReadFile(filepath.Join(root, "sys", "module", g.Driver, "version")); err == nil {
+				g.DriverVersion = strings.TrimSpace(string(b))
+			}
+		}
+		// amdgpu exports its VRAM; nvidia needs nvidia-smi (see linuxGPUs).
+		if b, err := os.ReadFile(filepath.Join(dev, "mem_info_vram_total")); err == nil {
+			if v, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64); err == nil {
+				g.VRAMMB = v / (1024 * 1024)
+			}
+		}
+		if strings.TrimSpace(g.Name) == "" {
+			g.Name = n
+		}
+		gpus = append(gpus, g)
+	}
+	return gpus, nil
+}
+
+// readHex returns a sysfs id like "0x10de" as "10de".
+func readHex(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(string(b))), "0x")
+}
+
+// pciIDs holds the vendor and device names of pci.ids, keyed "10de" and
+// "10de:1f82".
+type pciIDs map[string]string
+
+func (ids pciIDs) lookup(vendor, device string) (string, string) {
+	return ids[vendor], ids[vendor+":"+device]
+}
+
+func loadPCIIDs(root string) pciIDs {
+	for _, p := range []string{"usr/share/hwdata/pci.ids", "usr/share/misc/pci.ids", "usr/share/pci.ids"} {
+		f, err := os.Open(filepath.Join(root, p))
+		if err != nil {
+			continue
+		}
+		ids := parsePCIIDs(f)
+		f.Close()
+		return ids
+	}
+	return nil
+}
+
+// parsePCIIDs reads vendor lines ("10de  NVIDIA Corporation") and their
+// tab-indented device lines; subsystems (two tabs) and the class section
+// ("C 03 ...") are skipped.
+func parsePCIIDs(r io.Reader) pciIDs {
+	ids := pciIDs{}
+	vendor := ""
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		ln := sc.Text()
+		if ln == "" || ln[0] == '#' || strings.HasPrefix(ln, "\t\t") {
+			continue
+		}
+		if strings.HasPrefix(ln, "C ") {
+			break
+		}
+		id, name, ok := strings.Cut(strings.TrimLeft(ln, "\t"), "  ")
+		if !ok || len(id) != 4 {
+			continue
+		}
+		if ln[0] == '\t' {
+			if vendor != "" {
+				ids[vendor+":"+id] = strings.TrimSpace(name)
+			}
+			continue
+		}
+		vendor = id
+		ids[vendor] = strings.TrimSpace(name)
+	}
+	return ids
+}
+
+func linuxGPUs(c *collector) ([]GPUAdapter, error) {
+	gpus, err := readGPUs(c.fsRoot())
+	if err != nil {
+		return nil, err
+	}
+	for i := range gpus {
+		if gpus[i].Driver == "nvidia" && gpus[i].VRAMMB == 0 {
+			nvidiaSMI(c, gpus)
+			break
+		}
+	}
+	return gpus, nil
+}
+
+// nvidiaSMI fills VRAM for the proprietary driver, which does not export it
+// in sysfs. Cards are matched by PCI address; nvidia-smi writes the domain
+// with 8 digits ("00000000:01:00.0"), sysfs with 4.
+func nvidiaSMI(c *collector, gpus []GPUAdapter) {
+	out, err := c.runCmdTimeout(8, "nvidia-smi", "--query-gpu=pci.bus_id,memory.total", "--format=csv,noheader,nounits")
+	if err != nil {
+		c.addErr("gpu", err, "nvidia-smi")
+		return
+	}
+	busTail := func(s string) string {
+		_, t, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), ":")
+		return t
+	}
+	for _, ln := range strings.Split(out, "\n") {
+		bus, mem, ok := strings.Cut(ln, ",")
+		if !ok {
+			continue
+		}
+		mb, err := strconv.ParseInt(strings.TrimSpace(mem), 10, 64)
+		if err != nil {
+			continue
+		}
+		for i := range gpus {
+			if gpus[i].Driver == "nvidia" && busTail(gpus[i].PCIBus) == busTail(bus) {
+				gpus[i].VRAMMB = mb
+			}
+		}
+	}
+}
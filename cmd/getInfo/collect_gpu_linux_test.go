@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePCIIDs(t *testing.T) {
+	ids := parsePCIIDs(strings.NewReader("# comment\n10de  NVIDIA Corporation\n\t1f82  TU117 [GeForce GTX 1650]\n\t\t1043 8732  subsystem\n8086  Intel Corporation\n\nC 03  Display controller\n\t00  VGA compatible controller\n"))
+	if v, d := ids.lookup("10de", "1f82"); v != "NVIDIA Corporation" || d != "TU117 [GeForce GTX 1650]" {
+		t.Errorf("lookup = %q, %q", v, d)
+	}
+	if len(ids) != 3 {
+		t.Errorf("ids = %q", ids)
+	}
+}
+
+// The desktop tree has an Intel iGPU (card0, with a connector node) and an
+// AMD card that exports its VRAM; the discrete card comes first.
+func TestCollectGPULinux(t *testing.T) {
+	gpus, err := readGPUs(desktopRoot)
+	if err != nil || len(gpus) != 2 {
+		t.Fatalf("gpus = %+v, %v", gpus, err)
+	}
+	want := GPUAdapter{Name: "Intel Corporation CoffeeLake-S GT2 [UHD Graphics 630]", Vendor: "Intel Corporation", PCIID: "8086:3e92", PCIBus: "0000:00:02.0", Driver: "i915"}
+	if gpus[0] != want {
+		t.Errorf("card0 = %+v", gpus[0])
+	}
+
+	c := newCollector()
+	c.root = desktopRoot
+	v := collectGPU(c)
+	if v[FieldGPU.Key] != valueYes || v[FieldGPUDriver.Key] != "amdgpu" || v[FieldGPUVRAM.Key] != "8.0" ||
+		!strings.HasPrefix(v[FieldGPUModel.Key], "Advanced Micro Devices, Inc. [AMD/ATI] Navi 23 [Radeon RX 6600/6600 XT/6600M]; Intel") {
+		t.Errorf("values = %q", v)
+	}
+
+	c.root = armRoot
+	if v := collectGPU(c); v[FieldGPU.Key] != valueNo || len(c.errors()) != 0 {
+		t.Errorf("no drm: %q, %q", v, c.errors())
+	}
+}
+
+// nvidia-smi writes an 8-digit PCI domain; sysfs uses 4.
+func TestNvidiaSMI(t *testing.T) {
+	c := newCollector()
+	c.runner = stubRunner{"nvidia-smi --query-gpu=pci.bus_id,memory.total --format=csv,noheader,nounits": "00000000:01:00.0, 6144\n00000000:02:00.0, 4096"}
+	gpus := []GPUAdapter{{Driver: "i915", PCIBus: "0000:00:02.0"}, {Driver: "nvidia", PCIBus: "0000:01:00.0"}}
+	nvidiaSMI(c, gpus)
+	if gpus[0].VRAMMB != 0 || gpus[1].VRAMMB != 6144 {
+		t.Errorf("gpus = %+v", gpus)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVRAMGiB(t *testing.T) {
+	for mb, want := range map[int64]string{
+		32:   "0.1",
+		256:  "0.2",
+		512:  "0.5",
+		1023: "1.0",
+		1024: "1.0",
+		4096: "4.0",
+		6144: "6.0",
+		8176: "8.0",
+	} {
+		if got := vramGiB(mb); got != want {
+			t.Errorf("vramGiB(%d) = %q, want %q", mb, got, want)
+		}
+	}
+}
+
+// Fractional VRAM stays a numeric cell; values that only parse as floats
+// ("NaN", "1e3") are written as text.
+func TestXLSXNumericCells(t *testing.T) {
+	sh := xlsxSheet{
+		rows:    [][]string{{FieldGPUVRAM.Header}, {"0.5"}, {"NaN"}, {"1e3"}},
+		numeric: map[string]bool{FieldGPUVRAM.Header: true},
+	}
+	xml := sheetXML(sh)
+	if !strings.Contains(xml, `<c r="A2"><v>0.5</v></c>`) {
+		t.Errorf("0.5 not numeric: %s", xml)
+	}
+	for _, ref := range []string{"A3", "A4"} {
+		if !strings.Contains(xml, `<c r="`+ref+`" t="inlineStr">`) {
+			t.Errorf("%s not text: %s", ref, xml)
+		}
+	}
+}
+
+// A fractional VRAM survives the record and reaches every sink as a number.
+func TestVRAMRecord(t *testing.T) {
+	rec := newRecord(Values{FieldHost.Key: "PC-01", FieldGPUVRAM.Key: vramGiB(512)}, "run-1")
+	if rec.GPUVRAMGB == nil || *rec.GPUVRAMGB != 0.5 {
+		t.Fatalf("GPUVRAMGB = %v", rec.GPUVRAMGB)
+	}
+	if got := rec.Values()[FieldGPUVRAM.Key]; got != "0.5" {
+		t.Errorf("Values = %q", got)
+	}
+	b, _ := json.Marshal(rec)
+	if !strings.Contains(string(b), `"gpuVramGB":0.5`) {
+		t.Errorf("json: %s", b)
+	}
+	if r := newRecord(Values{FieldGPUVRAM.Key: "NaN"}, "run-2"); r.GPUVRAMGB != nil {
+		t.Errorf("NaN kept: %v", *r.GPUVRAMGB)
+	}
+
+	dir := t.TempDir()
+	cs := csvSink{path: filepath.Join(dir, CsvName), dialect: defaultDialect}
+	xs := xlsxSink{path: filepath.Join(dir, "inventario.xlsx"), errs: func() []string { return nil }}
+	for _, s := range []Sink{cs, xs} {
+		if err := s.Write(rec); err != nil {
+			t.Fatalf("%s: %v", s.Name(), err)
+		}
+	}
+	header, rows, err := readInventoryCSV(cs.path, defaultDialect)
+	if err != nil || len(rows) != 1 || rows[0][columnIndex(header)[FieldGPUVRAM.Header]] != "0.5" {
+		t.Errorf("csv: %q, %v", rows, err)
+	}
+	inv, _, err := readXLSX(xs.path)
+	if err != nil || len(inv) != 2 || inv[1][columnIndex(inv[0])[FieldGPUVRAM.Header]] != "0.5" {
+		t.Errorf("xlsx: %q, %v", inv, err)
+	}
+}
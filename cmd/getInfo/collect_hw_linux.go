@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Linux readers take the filesystem root (normally "/") so they can be
+// pointed at a captured fixture tree.
+
+// DMI attributes as exported by the kernel (serial/uuid need root).
+func readDMI(root, name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(root, "sys", "class", "dmi", "id", name))
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(b))
+	if v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+func readCPUModel(root string) (string, error) {
+	f, err := os.Open(filepath.Join(root, "proc", "cpuinfo"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	// x86 has "model name"; ARM boards often only have "Hardware"/"Processor".
+	var alt string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		switch strings.ToLower(k) {
+		case "model name":
+			if v != "" {
+				return v, nil
+			}
+		case "hardware", "processor":
+			// "processor : 0" is just an index on x86.
+			if alt == "" && v != "" && strings.Trim(v, "0123456789") != "" {
+				alt = v
+			}
+		}
+	}
+	if alt != "" {
+		return alt, nil
+	}
+	return "", ErrNotFound
+}
+
+// MemTotal in bytes.
+func readMemTotal(root string) (int64, error) {
+	f, err := os.Open(filepath.Join(root, "proc", "meminfo"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if strings.HasPrefix(sc.Text(), "MemTotal:") {
+			kb, err := parseInt64Any(sc.Text())
+			if err != nil {
+				return 0, err
+			}
+			return kb * 1024, nil
+		}
+	}
+	return 0, ErrNotFound
+}
+
+func linuxSerial(c *collector) string {
+	if info := c.smbios(); info != nil && info.Serial != "" {
+		return info.Serial
+	}
+	v, err := readDMI(c.fsRoot(), "product_serial")
+	if err != nil {
+		c.addErr("serial", err, "")
+	}
+	return v
+}
+
+func linuxUUID(c *collector) string {
+	if info := c.smbios(); info != nil && info.UUID != "" {
+		return info.UUID
+	}
+	v, err := readDMI(c.fsRoot(), "product_uuid")
+	if err != nil {
+		c.addErr("uuid_smbios", err, "")
+	}
+	return strings.ToUpper(v) // match the Windows format
+}
+
+func linuxCPU(c *collector) string {
+	v, err := readCPUModel(c.fsRoot())
+	if err != nil {
+		c.addErr("cpu", err, "")
+	}
+	return v
+}
+
+func linuxTotalRAMGiB(c *collector) string {
+	b, err := readMemTotal(c.fsRoot())
+	if err != nil {
+		c.addErr("ram_total", err, "")
+		return ""
+	}
+	return ToStr(toGiB(b))
+}
+
+// Module details only exist in the SMBIOS table as well.
+func linuxRAMModules(c *collector) []MemoryModule {
+	if info := c.smbios(); info != nil {
+		return info.memoryModules()
+	}
+	return nil
+}
+
+// Slots only exist in the SMBIOS table; sysfs has no per-DIMM view.
+func linuxRAMSlots(c *collector) (used int64, total int64, okUsed bool, okTotal bool) {
+	if info := c.smbios(); info != nil {
+		if used, total, ok := info.memorySlots(); ok {
+			return used, total, true, true
+		}
+	}
+	c.addErr("ram_slots", ErrNotFound, "")
+	return 0, 0, false, false
+}
@@ -0,0 +1,53 @@
+package main
+
+import "testing"
+
+// Captured trees under testdata/linux: an x86 desktop with DMI and an ARM
+// board without it.
+const (
+	desktopRoot = "testdata/linux/desktop"
+	armRoot     = "testdata/linux/arm"
+)
+
+func TestReadDMI(t *testing.T) {
+	for _, c := range []struct {
+		root, name, want string
+		ok               bool
+	}{
+		{desktopRoot, "product_serial", "7XK3Q93", true},
+		{desktopRoot, "product_uuid", "4c4c4544-0058-4b10-8033-b7c04f513933", true},
+		{desktopRoot, "board_serial", "", false}, // blank
+		{desktopRoot, "chassis_serial", "", false},
+		{armRoot, "product_serial", "", false},
+	} {
+		got, err := readDMI(c.root, c.name)
+		if got != c.want || (err == nil) != c.ok {
+			t.Errorf("readDMI(%s, %s) = %q, %v", c.root, c.name, got, err)
+		}
+	}
+}
+
+func TestReadCPUModel(t *testing.T) {
+	for root, want := range map[string]string{
+		desktopRoot: "Intel(R) Core(TM) i5-9500 CPU @ 3.00GHz",
+		armRoot:     "BCM2835", // no "model name"; the processor index is skipped
+	} {
+		if got, err := readCPUModel(root); got != want || err != nil {
+			t.Errorf("readCPUModel(%s) = %q, %v", root, got, err)
+		}
+	}
+	if _, err := readCPUModel(t.TempDir()); err == nil {
+		t.Error("no cpuinfo: no error")
+	}
+}
+
+func TestReadMemTotal(t *testing.T) {
+	for root, want := range map[string]int64{
+		desktopRoot: 16318508 * 1024,
+		armRoot:     3884056 * 1024, // MemTotal not on the first line
+	} {
+		if got, err := readMemTotal(root); got != want || err != nil {
+			t.Errorf("readMemTotal(%s) = %d, %v", root, got, err)
+		}
+	}
+}
@@ -9,53 +9,17 @@ import (
 
 func strconvFormatInt(i int64) string { return strconv.FormatInt(i, 10) }
 
-func getHostname(c *collector) string {
-	h, err := os.Hostname()
-	if err != nil {
-		c.addErr("hostname", err, "")
-		return ""
-	}
-	return strings.TrimSpace(h)
-}
-
-func getCurrentUser(c *collector) string {
-	out, err := runCMD("whoami")
-	if err == nil && out != "" {
-		return strings.TrimSpace(out)
-	}
-	u := os.Getenv("USERNAME")
-	if u == "" {
-		c.addErr("usuario", err, "USERNAME vazio")
-	}
-	return u
-}
+func ToStr(i int64) string { return strconvFormatInt(i) }
 
-func getWindowsVersion(c *collector) string {
-	out, err := runCMD(`reg query "HKLM\SOFTWARE\Microsoft\Windows NT\CurrentVersion" /v ProductName`)
-	if err == nil && out != "" {
-		for _, ln := range strings.Split(out, "\n") {
-			if strings.Contains(ln, "ProductName") {
-				idx := strings.Index(ln, "REG_SZ")
-				if idx >= 0 {
-					product := strings.TrimSpace(ln[idx+len("REG_SZ"):])
-					switch {
-					case strings.Contains(product, "Windows 11"):
-						return "11"
-					case strings.Contains(product, "Windows 10"):
-						return "10"
-					}
-				}
-			}
+func getHostname(c *collector) string {
+	return c.fact("hostname", func() string {
+		h, err := os.Hostname()
+		if err != nil {
+			c.addErr("hostname", err, "")
+			return ""
 		}
-	}
-	out2, _ := runCMD("ver")
-	if strings.Contains(out2, "Version 10.") {
-		return "10"
-	}
-	if strings.Contains(out2, "Version 11.") {
-		return "11"
-	}
-	return ""
+		return strings.TrimSpace(h)
+	})
 }
 
 // --- helpers (shared across files) ---
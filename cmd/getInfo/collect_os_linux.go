@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+func linuxCurrentUser(c *collector) string {
+	if u := c.fact("user", currentUsername); u != "" {
+		return u
+	}
+	u := c.getenv("USER")
+	if u == "" {
+		c.addErr("user", ErrNotFound, tr("env.empty", "USER"))
+	}
+	return u
+}
+
+func currentUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// "NAME VERSION_ID" from os-release (e.g. "Ubuntu 22.04"); the Win column
+// holds this on Linux.
+func readOSRelease(root string) (string, error) {
+	var f *os.File
+	var err error
+	for _, p := range []string{"etc/os-release", "usr/lib/os-release"} {
+		if f, err = os.Open(filepath.Join(root, p)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	kv := map[string]string{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if ok {
+			kv[k] = strings.Trim(v, `"'`)
+		}
+	}
+	if kv["NAME"] != "" && kv["VERSION_ID"] != "" {
+		return kv["NAME"] + " " + kv["VERSION_ID"], nil
+	}
+	if kv["PRETTY_NAME"] != "" {
+		return kv["PRETTY_NAME"], nil
+	}
+	return "", ErrNotFound
+}
+
+func linuxOSVersion(c *collector) string {
+	v, err := readOSRelease(c.fsRoot())
+	if err != nil {
+		c.addErr("os_version", err, "")
+	}
+	return v
+}
@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestReadOSRelease(t *testing.T) {
+	for root, want := range map[string]string{
+		desktopRoot: "Ubuntu 22.04",
+		armRoot:     "Raspbian GNU/Linux 11 (bullseye)", // /usr/lib copy, PRETTY_NAME only
+	} {
+		if got, err := readOSRelease(root); got != want || err != nil {
+			t.Errorf("readOSRelease(%s) = %q, %v", root, got, err)
+		}
+	}
+	if _, err := readOSRelease(t.TempDir()); err == nil {
+		t.Error("no os-release: no error")
+	}
+}
+
+// The user comes from the recording, USER only as a fallback.
+func TestLinuxCurrentUserReplay(t *testing.T) {
+	for _, tc := range []struct {
+		facts map[string]string
+		want  string
+	}{
+		{map[string]string{"user": "ana", "env:USER": "root"}, "ana"},
+		{map[string]string{"env:USER": "bruno"}, "bruno"},
+		{nil, ""},
+	} {
+		c := &collector{log: &errLog{}, runner: &replayRunner{facts: tc.facts}}
+		if u := linuxCurrentUser(c); u != tc.want {
+			t.Errorf("%v: user = %q, want %q", tc.facts, u, tc.want)
+		}
+		if logged := len(c.errors()) > 0; logged != (tc.want == "") {
+			t.Errorf("%v: logged %q", tc.facts, c.errors())
+		}
+	}
+}
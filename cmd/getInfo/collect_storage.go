@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// PhysicalDisk is one disk as the OS sees it (RAID volumes show as one).
+type PhysicalDisk struct {
+	Name   string `json:"name"` // "0" (Windows disk number), "nvme0n1"
+	Model  string `json:"model,omitempty"`
+	Serial string `json:"serial,omitempty"`
+	Bus    string `json:"bus,omitempty"`   // NVMe, SATA, SAS, USB, RAID, Virtual...
+	Media  string `json:"media,omitempty"` // mediaSSD, mediaHDD; empty = unknown
+	SizeGB int64  `json:"sizeGB"`
+	System bool   `json:"system"` // holds the system volume
+}
+
+// Volume is one mounted filesystem with a drive letter (Windows) or a
+// block device behind it (Linux).
+type Volume struct {
+	Mount  string `json:"mount"` // "C:", "/"
+	Label  string `json:"label,omitempty"`
+	FS     string `json:"fs,omitempty"`
+	SizeGB int64  `json:"sizeGB"`
+	FreeGB int64  `json:"freeGB"`
+	Disk   string `json:"disk,omitempty"` // PhysicalDisk.Name
+	System bool   `json:"system"`
+}
+
+const (
+	mediaSSD = "SSD"
+	mediaHDD = "HDD"
+)
+
+// Keys carrying the JSON lists from the collector to newRecord; not columns.
+const (
+	physicalDisksKey = "physical_disks"
+	volumesKey       = "volumes"
+)
+
+type storageInfo struct {
+	disks   []PhysicalDisk
+	volumes []Volume
+}
+
+// markSystem flags the volume mounted at sysMount and the disk behind it.
+func (s *storageInfo) markSystem(sysMount string) {
+	for i := range s.volumes {
+		v := &s.volumes[i]
+		if !strings.EqualFold(v.Mount, sysMount) {
+			continue
+		}
+		v.System = true
+		for j := range s.disks {
+			if s.disks[j].Name == v.Disk {
+				s.disks[j].System = true
+			}
+		}
+	}
+}
+
+func (s *storageInfo) systemDisk() *PhysicalDisk {
+	for i := range s.disks {
+		if s.disks[i].System {
+			return &s.disks[i]
+		}
+	}
+	return nil
+}
+
+// isSSD answers for one disk: NVMe is always flash; otherwise the media
+// type, and as a last resort the model name.
+func (d *PhysicalDisk) isSSD() (string, bool) {
+	switch {
+	case d.Media == mediaSSD, strings.EqualFold(d.Bus, "NVMe"):
+		return valueYes, true
+	case d.Media == mediaHDD:
+		return valueNo, true
+	case strings.Contains(strings.ToUpper(d.Model), "SSD"):
+		return valueYes, true
+	}
+	return "", false
+}
+
+// storageCache holds the disk/volume snapshot shared by the ssd and storage
+// collectors.
+type storageCache struct {
+	mu   sync.Mutex
+	done bool
+	info *storageInfo
+	err  error
+}
+
+// storageInfo returns the snapshot, read once per run by whichever collector
+// asks first, under that collector's timeout. A read cut short by the timeout
+// is not kept: the next collector tries again.
+func (c *collector) storageInfo() (*storageInfo, error) {
+	if c.storage == nil {
+		return readStorage(c)
+	}
+	s := c.storage
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.done {
+		s.info, s.err = readStorage(c)
+		s.done = c.ctx.Err() == nil
+	}
+	return s.info, s.err
+}
+
+// getIsSSD reports the disk that holds the system volume; a data SSD next
+// to a system HDD does not count.
+func getIsSSD(c *collector) string {
+	s, err := c.storageInfo()
+	if err != nil {
+		c.addErr("ssd", err, "")
+		return ""
+	}
+	d := s.systemDisk()
+	if d == nil {
+		c.addErr("ssd", ErrNotFound, tr("storage.no_system_disk"))
+		return ""
+	}
+	v, ok := d.isSSD()
+	if !ok {
+		c.addErr("ssd", ErrNotFound, d.Name+" "+d.Model)
+	}
+	return v
+}
+
+// collectStorage fills the Disks column ("NVMe SSD 477GB; SATA HDD 932GB",
+// system disk first) and the detailed lists of the JSON record.
+func collectStorage(c *collector) Values {
+	s, err := c.storageInfo()
+	if err != nil {
+		c.addErr("storage", err, "")
+		return Values{}
+	}
+	var parts []string
+	for _, sys := range []bool{true, false} {
+		for _, d := range s.disks {
+			if d.System == sys {
+				parts = append(parts, d.summary())
+			}
+		}
+	}
+	v := Values{FieldDisks.Key: strings.Join(parts, "; ")}
+	if b, err := json.Marshal(s.disks); err == nil && len(s.disks) > 0 {
+		v[physicalDisksKey] = string(b)
+	}
+	if b, err := json.Marshal(s.volumes); err == nil && len(s.volumes) > 0 {
+		v[volumesKey] = string(b)
+	}
+	return v
+}
+
+func (d PhysicalDisk) summary() string {
+	var f []string
+	for _, s := range []string{d.Bus, d.Media, ToStr(d.SizeGB) + "GB"} {
+		if s != "" {
+			f = append(f, s)
+		}
+	}
+	return strings.Join(f, " ")
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// Windows 7 / WinPE: no Get-PhysicalDisk, so WMIC lists the disks and the
+// system disk's model decides SSD.
+func TestWindowsStorageWMIC(t *testing.T) {
+	c := newCollector()
+	c.plat = windowsPlatform
+	c.runner = stubRunner{
+		"cmd /C wmic diskdrive get Index,InterfaceType,Model,Size /value": "\r\r\n\r\r\nIndex=0\r\r\nInterfaceType=IDE\r\r\nModel=KINGSTON SA400S37240G SSD\r\r\nSize=240054796800\r\r\n\r\r\n" +
+			"Index=1\r\r\nInterfaceType=IDE\r\r\nModel=WDC WD10EZEX-08WN4A0\r\r\nSize=1000202273280\r\r\n",
+		"cmd /C wmic path Win32_LogicalDiskToPartition get Antecedent,Dependent /value": "\r\r\n" +
+			`Antecedent=\\PC-01\root\cimv2:Win32_DiskPartition.DeviceID="Disk #1, Partition #0"` + "\r\r\n" +
+			`Dependent=\\PC-01\root\cimv2:Win32_LogicalDisk.DeviceID="D:"` + "\r\r\n\r\r\n" +
+			`Antecedent=\\PC-01\root\cimv2:Win32_DiskPartition.DeviceID="Disk #0, Partition #1"` + "\r\r\n" +
+			`Dependent=\\PC-01\root\cimv2:Win32_LogicalDisk.DeviceID="C:"` + "\r\r\n",
+	}
+	if got := getIsSSD(c); got != valueYes {
+		t.Errorf("ssd = %q, errors %q", got, c.errors())
+	}
+	v := collectStorage(c)
+	if got := v[FieldDisks.Key]; got != "IDE 224GB; IDE 932GB" {
+		t.Errorf("disks = %q", got)
+	}
+}
+
+// The snapshot is read with the asking collector's context, and a read cut
+// short by its timeout is not kept for the next one.
+func TestStorageInfoContext(t *testing.T) {
+	c := newCollector()
+	c.plat = windowsPlatform
+	reads := 0
+	c.runner = runnerFunc(func(ctx context.Context, cmd Command) (string, error) {
+		reads++
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "P|0|Samsung SSD 870|S1|SATA|SSD|500107862016\nL|C|0\n", nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.withContext(ctx).storageInfo(); err == nil {
+		t.Fatal("no error under a cancelled context")
+	}
+	s, err := c.withContext(context.Background()).storageInfo()
+	if err != nil || s.systemDisk() == nil {
+		t.Fatalf("retry: %v", err)
+	}
+	n := reads
+	c.withContext(context.Background()).storageInfo()
+	if reads != n {
+		t.Error("read again after a good snapshot")
+	}
+}
+
+type runnerFunc func(ctx context.Context, cmd Command) (string, error)
+
+func (f runnerFunc) Run(ctx context.Context, cmd Command) (string, error) { return f(ctx, cmd) }
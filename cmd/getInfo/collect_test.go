@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testCollectOptions = collectOptions{workers: 4, perCollector: time.Second, total: 2 * time.Second}
+
+// sleeper is a one-column collector that takes d (or until its context ends).
+func sleeper(name string, d time.Duration) Collector {
+	return single(name, Field{name, name}, nil, func(c *collector) string {
+		select {
+		case <-time.After(d):
+			return name
+		case <-c.ctx.Done():
+			return ""
+		}
+	})
+}
+
+// A collector past its own timeout is logged and left empty; the others
+// still fill their columns.
+func TestCollectTimeout(t *testing.T) {
+	c := newCollector()
+	cols := []Collector{sleeper("a", 0), sleeper("slow", time.Hour), sleeper("b", 10*time.Millisecond)}
+	vals := collectAll(c, cols, collectOptions{workers: 2, perCollector: 50 * time.Millisecond, total: time.Second})
+	if vals["a"] != "a" || vals["b"] != "b" || vals["slow"] != "" {
+		t.Errorf("vals = %v", vals)
+	}
+	errs := c.errors()
+	if len(errs) != 1 || !strings.Contains(errs[0], "slow: tempo esgotado") {
+		t.Errorf("errors = %q", errs)
+	}
+}
+
+// The overall deadline ends the run even when every collector is within its
+// own timeout; what is missing is logged once per collector.
+func TestCollectDeadline(t *testing.T) {
+	c := newCollector()
+	cols := []Collector{sleeper("a", 0), sleeper("x", time.Hour), sleeper("y", time.Hour)}
+	start := time.Now()
+	vals := collectAll(c, cols, collectOptions{workers: 1, perCollector: time.Hour, total: 100 * time.Millisecond})
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("took %v", d)
+	}
+	if vals["a"] != "a" {
+		t.Errorf("vals = %v", vals)
+	}
+	errs := c.errors()
+	if len(errs) != 2 || !strings.Contains(errs[0], "x: prazo total esgotado") || !strings.Contains(errs[1], "y: prazo total esgotado") {
+		t.Errorf("errors = %q", errs)
+	}
+}
+
+// No more than opts.workers collectors run at once.
+func TestCollectWorkers(t *testing.T) {
+	var running, peak atomic.Int32
+	var cols []Collector
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		cols = append(cols, single(name, Field{name, name}, nil, func(c *collector) string {
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			return name
+		}))
+	}
+	vals := collectAll(newCollector(), cols, collectOptions{workers: 2, perCollector: time.Second, total: time.Second})
+	if len(vals) != len(cols) || peak.Load() > 2 {
+		t.Errorf("%d values, peak %d", len(vals), peak.Load())
+	}
+}
+
+// A collector that overruns its timeout must not log after collectAll has
+// returned, when the error file is already being written.
+func TestCollectAllLateErrors(t *testing.T) {
+	c := newCollector()
+	c.plat = nativePlatform
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	slow := funcCollector{name: "slow", fn: func(cc *collector) Values {
+		<-release
+		cc.addErr("slow", ErrNotFound, "late")
+		close(finished)
+		return Values{FieldHost.Key: "late"}
+	}}
+	fast := funcCollector{name: "fast", fn: func(cc *collector) Values {
+		cc.addErr("fast", ErrNotFound, "")
+		return Values{FieldSN.Key: "SN1"}
+	}}
+
+	vals := collectAll(c, []Collector{slow, fast}, collectOptions{workers: 2, perCollector: 50 * time.Millisecond, total: time.Second})
+	close(release)
+	<-finished
+
+	if vals[FieldSN.Key] != "SN1" || vals[FieldHost.Key] != "" {
+		t.Errorf("vals = %v", vals)
+	}
+	errs := strings.Join(c.errors(), "\n")
+	if !strings.Contains(errs, "fast: ") || !strings.Contains(errs, "slow: ") || strings.Contains(errs, "late") {
+		t.Errorf("errors:\n%s", errs)
+	}
+}
@@ -1,11 +1,523 @@
 package main
 
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
 // File names must stay in Portuguese for users/operators.
 const (
-	CsvName    = "inventario.csv"
-	ErrLogName = "inventario_erros.txt"
+	CsvName      = "inventario.csv"
+	ErrLogName   = "inventario_erros.txt"
+	HistoryName  = "inventario_historico.csv"
+	SpoolDirName = "spool"
+	XLSXName     = "inventario.xlsx"
+	JSONDirName  = "json"
+	NDJSONName   = "inventario.ndjson"
 )
 
-// Hardcoded AnyDesk password (fast rollout). Change before build.
+// Compiled-in AnyDesk password, used only when config.json has none.
 // NOTE: running as Admin is required to set the password.
 const AnyDeskPassword = ""
+
+// Collection limits: collectors run on a small worker pool, each with its own
+// timeout, and everything still missing at the overall deadline stays empty.
+const (
+	CollectWorkers   = 4
+	CollectorTimeout = 20 * time.Second
+	CollectDeadline  = 60 * time.Second
+)
+
+// HTTP upload defaults (see httpsink.go).
+const (
+	HTTPTimeout = 15 // seconds per attempt
+	HTTPRetries = 3
+	HTTPBackoff = 2 // seconds, doubled each retry
+)
+
+// Output file locking (see lockfile.go).
+const (
+	LockWait  = 30 * time.Second
+	LockStale = 120 * time.Second
+)
+
+const (
+	modeAppend = "append"
+	modeUpsert = "upsert"
+
+	quoteMinimal = "minimal"
+	quoteAll     = "all"
+	eolCRLF      = "crlf"
+	eolLF        = "lf"
+)
+
+// config/config.json lives next to the exe and is created on first run.
+const configRel = "config/config.json"
+
+// configSettle: a config.json this young that is empty or cut short is being
+// created by another run (older builds wrote it in place); use the defaults.
+const configSettle = 5 * time.Second
+
+type Config struct {
+	// Collector name -> enabled. Missing names are enabled.
+	Collection map[string]bool  `json:"collection"`
+	Output     OutputConfig     `json:"output"`
+	CSV        CSVConfig        `json:"csv"`
+	Vocabulary VocabularyConfig `json:"vocabulary"`
+	HTTP       HTTPConfig       `json:"http"`
+	UI         UIConfig         `json:"ui"`
+	Timeouts   TimeoutConfig    `json:"timeouts"`
+	AnyDesk    AnyDeskConfig    `json:"anydesk"`
+}
+
+type OutputConfig struct {
+	Dir      string `json:"dir"` // "" = exe directory; relative = from exe directory
+	CSVFile  string `json:"csvFile"`
+	ErrorLog string `json:"errorLog"`
+
+	// CSV write mode: "append" (one row per run, audit trail) or "upsert"
+	// (one row per machine, keyed on Identity headers, e.g. ["SN","UUID"]).
+	Mode        string   `json:"mode"`
+	Identity    []string `json:"identity"`
+	HistoryFile string   `json:"historyFile"` // upsert: superseded rows; "" = discard
+
+	// CSV rows that cannot be written (file open in Excel, share offline) are
+	// queued here, relative to the exe directory, and retried next run.
+	Spool    bool   `json:"spool"`
+	SpoolDir string `json:"spoolDir"`
+
+	// Sinks; paths below are relative to Dir.
+	CSV        bool   `json:"csv"`
+	Excel      bool   `json:"excel"` // native .xlsx, sheets Inventario + Erros
+	ExcelFile  string `json:"excelFile"`
+	JSON       bool   `json:"json"` // one <host>_<id>.json per machine in JSONDir
+	JSONDir    string `json:"jsonDir"`
+	NDJSON     bool   `json:"ndjson"` // append-only log of every run
+	NDJSONFile string `json:"ndjsonFile"`
+
+	// Cleaning of formula-like values and control characters in CSV/XLSX:
+	// "prefix", "strip" or "reject" (see sanitize.go).
+	Sanitize string `json:"sanitize"`
+
+	ShowSummaryInConsole bool `json:"showSummaryInConsole"`
+}
+
+// CSVConfig is the dialect of every CSV written (inventory, history, export).
+// The defaults open directly in Excel, PT-BR included.
+type CSVConfig struct {
+	Delimiter  string `json:"delimiter"`  // one character: "," ";" "\t" "|"
+	Quoting    string `json:"quoting"`    // "minimal" (only when needed) or "all"
+	BOM        bool   `json:"bom"`        // UTF-8 BOM, so Excel reads accents
+	SepLine    bool   `json:"sepLine"`    // "sep=," first line, so Excel splits columns
+	LineEnding string `json:"lineEnding"` // "crlf" or "lf"
+}
+
+// VocabularyConfig is how yes/no and unknown values are written in the CSV,
+// the workbook and the console. JSON outputs keep real booleans and nulls.
+// It is data, not UI: ui.language and --lang do not change it, so a shared
+// inventario.csv never mixes Sim/Yes/Si. Empty Yes/No mean Sim/Nao.
+type VocabularyConfig struct {
+	Yes     string `json:"yes"`
+	No      string `json:"no"`
+	Unknown string `json:"unknown"` // empty boolean/numeric columns
+}
+
+// HTTPConfig is the central endpoint every record is POSTed to as JSON.
+type HTTPConfig struct {
+	Enabled bool              `json:"enabled"`
+	URL     string            `json:"url"`
+	Token   string            `json:"token"`   // sent as "Authorization: Bearer <token>"
+	Headers map[string]string `json:"headers"` // extra request headers
+
+	// TLS: CA bundle (PEM) added to the system roots; client certificate and
+	// key (PEM) for mutual TLS. Relative paths are from the exe directory.
+	CAFile     string `json:"caFile"`
+	ClientCert string `json:"clientCert"`
+	ClientKey  string `json:"clientKey"`
+
+	TimeoutSeconds int `json:"timeoutSeconds"` // per attempt
+	Retries        int `json:"retries"`        // extra attempts on network errors, 408, 429, 5xx
+	BackoffSeconds int `json:"backoffSeconds"` // first wait, doubled each retry
+}
+
+type UIConfig struct {
+	// Prompt for Patrimonio/Nome/Local when started without arguments.
+	InteractiveIfNoArgs bool `json:"interactiveIfNoArgs"`
+	// Messages and labels: "pt-BR", "en", "es"; "" = system language.
+	// --lang before the command overrides it.
+	Language string `json:"language"`
+}
+
+type TimeoutConfig struct {
+	Workers          int `json:"workers"`
+	CollectorSeconds int `json:"collectorSeconds"`
+	TotalSeconds     int `json:"totalSeconds"`
+
+	// Shared output files: how long to wait for another run's lock, and age
+	// after which a lock left by a crashed run is broken.
+	LockWaitSeconds  int `json:"lockWaitSeconds"`
+	LockStaleSeconds int `json:"lockStaleSeconds"`
+}
+
+type AnyDeskConfig struct {
+	SetPassword bool   `json:"setPassword"`
+	Password    string `json:"password"`
+}
+
+func defaultConfig() *Config {
+	col := map[string]bool{}
+	for _, c := range registry {
+		col[c.Name()] = true
+	}
+	return &Config{
+		Collection: col,
+		Output: OutputConfig{
+			CSVFile:              CsvName,
+			ErrorLog:             ErrLogName,
+			Mode:                 modeAppend,
+			Identity:             []string{FieldSN.Header, FieldUUID.Header},
+			HistoryFile:          HistoryName,
+			Spool:                true,
+			SpoolDir:             SpoolDirName,
+			CSV:                  true,
+			ExcelFile:            XLSXName,
+			JSONDir:              JSONDirName,
+			NDJSONFile:           NDJSONName,
+			Sanitize:             sanitizePrefix,
+			ShowSummaryInConsole: true,
+		},
+		CSV: CSVConfig{
+			Delimiter:  string(defaultDialect.comma),
+			Quoting:    quoteMinimal,
+			BOM:        defaultDialect.bom,
+			SepLine:    defaultDialect.sepLine,
+			LineEnding: eolCRLF,
+		},
+		HTTP: HTTPConfig{
+			Headers:        map[string]string{},
+			TimeoutSeconds: HTTPTimeout,
+			Retries:        HTTPRetries,
+			BackoffSeconds: HTTPBackoff,
+		},
+		Vocabulary: VocabularyConfig{Yes: defaultYes, No: defaultNo},
+		UI:         UIConfig{InteractiveIfNoArgs: true},
+		Timeouts: TimeoutConfig{
+			Workers:          CollectWorkers,
+			CollectorSeconds: int(CollectorTimeout / time.Second),
+			TotalSeconds:     int(CollectDeadline / time.Second),
+			LockWaitSeconds:  int(LockWait / time.Second),
+			LockStaleSeconds: int(LockStale / time.Second),
+		},
+		AnyDesk: AnyDeskConfig{SetPassword: AnyDeskPassword != "", Password: AnyDeskPassword},
+	}
+}
+
+// loadConfig reads base/config/config.json, writing the defaults first if the
+// file does not exist. Keys missing from the file keep their defaults.
+func loadConfig(base string) (*Config, error) { return readConfig(base, true) }
+
+// peekConfig is loadConfig for commands that must not leave files behind
+// (show, doctor, export): a missing file means the defaults.
+func peekConfig(base string) (*Config, error) { return readConfig(base, false) }
+
+func readConfig(base string, create bool) (*Config, error) {
+	path := filepath.Join(base, filepath.FromSlash(configRel))
+	cfg := defaultConfig()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if !create {
+			return cfg, nil
+		}
+		if err := writeConfig(path, cfg); err != nil {
+			return nil, trErr("config.create", configRel, err)
+		}
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", configRel, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF})))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		if truncatedConfig(path, err) {
+			return defaultConfig(), nil
+		}
+		return nil, fmt.Errorf("%s: %s", configRel, describeJSONError(data, err))
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", configRel, err)
+	}
+	return cfg, nil
+}
+
+// truncatedConfig reports a decode error caused by an empty or half-written
+// file that was modified just now.
+func truncatedConfig(path string, err error) bool {
+	if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false
+	}
+	fi, serr := os.Stat(path)
+	return serr == nil && time.Since(fi.ModTime()) < configSettle
+}
+
+// writeConfig writes the file next to its final name and moves it into place,
+// so a concurrent run never reads a partial file. If another run won the
+// race, its copy (the same defaults) is kept.
+func writeConfig(path string, cfg *Config) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".config-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(append(data, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	// Link fails when the file exists. FAT volumes (USB sticks) have no hard
+	// links; rename replaces atomically there.
+	if err := os.Link(tmp.Name(), path); err == nil || errors.Is(err, fs.ErrExist) {
+		return nil
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// describeJSONError adds the line number to syntax/type errors so operators
+// can find the typo in Notepad.
+func describeJSONError(data []byte, err error) string {
+	var off int64 = -1
+	var se *json.SyntaxError
+	var te *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &se):
+		off = se.Offset
+	case errors.As(err, &te):
+		off = te.Offset
+	}
+	if off < 0 {
+		return err.Error()
+	}
+	line := 1 + bytes.Count(data[:min(int(off), len(data))], []byte("\n"))
+	return tr("config.line", line, err)
+}
+
+func (cfg *Config) validate() error {
+	var problems []string
+	known := map[string]bool{}
+	var names []string
+	for _, c := range registry {
+		known[c.Name()] = true
+		names = append(names, c.Name())
+	}
+	var unknown []string
+	for k := range cfg.Collection {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	for _, k := range unknown {
+		problems = append(problems, tr("config.unknown_col", k, strings.Join(names, ", ")))
+	}
+
+	if strings.TrimSpace(cfg.Output.CSVFile) == "" {
+		problems = append(problems, tr("config.empty", "output.csvFile"))
+	}
+	if strings.TrimSpace(cfg.Output.ErrorLog) == "" {
+		problems = append(problems, tr("config.empty", "output.errorLog"))
+	}
+	switch cfg.Output.Mode {
+	case modeAppend:
+	case modeUpsert:
+		if len(cfg.Output.Identity) == 0 {
+			problems = append(problems, tr("config.identity_needed"))
+		}
+	default:
+		problems = append(problems, tr("config.bad_mode", cfg.Output.Mode))
+	}
+	headers := map[string]bool{}
+	for _, h := range Headers() {
+		headers[h] = true
+	}
+	for _, h := range cfg.Output.Identity {
+		if !headers[h] {
+			problems = append(problems, tr("config.bad_identity", h))
+		}
+	}
+	if cfg.Output.Spool && strings.TrimSpace(cfg.Output.SpoolDir) == "" {
+		problems = append(problems, tr("config.empty_when", "output.spoolDir", "output.spool"))
+	}
+	if cfg.Output.Excel && !strings.HasSuffix(strings.ToLower(cfg.Output.ExcelFile), ".xlsx") {
+		problems = append(problems, tr("config.xlsx_ext"))
+	}
+	if cfg.Output.JSON && strings.TrimSpace(cfg.Output.JSONDir) == "" {
+		problems = append(problems, tr("config.empty_when", "output.jsonDir", "output.json"))
+	}
+	if cfg.Output.NDJSON && strings.TrimSpace(cfg.Output.NDJSONFile) == "" {
+		problems = append(problems, tr("config.empty_when", "output.ndjsonFile", "output.ndjson"))
+	}
+	switch cfg.Output.Sanitize {
+	case sanitizePrefix, sanitizeStrip, sanitizeReject:
+	default:
+		problems = append(problems, tr("config.bad_sanitize", cfg.Output.Sanitize))
+	}
+	if h := cfg.HTTP; h.Enabled {
+		if u, err := url.Parse(h.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, tr("config.bad_url", h.URL))
+		}
+		if (h.ClientCert == "") != (h.ClientKey == "") {
+			problems = append(problems, tr("config.client_pair"))
+		}
+		if h.TimeoutSeconds < 1 {
+			problems = append(problems, tr("config.bad_positive", "http.timeoutSeconds", h.TimeoutSeconds))
+		}
+		if h.Retries < 0 || h.Retries > 10 {
+			problems = append(problems, tr("config.bad_retries", h.Retries))
+		}
+		if h.BackoffSeconds < 0 {
+			problems = append(problems, tr("config.bad_negative", "http.backoffSeconds", h.BackoffSeconds))
+		}
+	}
+	if !cfg.Output.CSV && !cfg.Output.Excel && !cfg.Output.JSON && !cfg.Output.NDJSON {
+		problems = append(problems, tr("config.no_output"))
+	}
+
+	if r := []rune(cfg.CSV.Delimiter); len(r) != 1 || strings.ContainsRune("\"\r\n\ufffd", r[0]) {
+		problems = append(problems, tr("config.bad_delimiter", cfg.CSV.Delimiter))
+	}
+	if cfg.CSV.Quoting != quoteMinimal && cfg.CSV.Quoting != quoteAll {
+		problems = append(problems, tr("config.bad_quoting", cfg.CSV.Quoting))
+	}
+	if cfg.CSV.LineEnding != eolCRLF && cfg.CSV.LineEnding != eolLF {
+		problems = append(problems, tr("config.bad_eol", cfg.CSV.LineEnding))
+	}
+	if v := cfg.Vocabulary; (v.Yes == "") != (v.No == "") || (v.Yes != "" && v.Yes == v.No) {
+		problems = append(problems, tr("config.bad_vocabulary"))
+	}
+	if _, ok := normalizeLanguage(cfg.UI.Language); cfg.UI.Language != "" && !ok {
+		problems = append(problems, tr("config.bad_language", cfg.UI.Language))
+	}
+
+	t := cfg.Timeouts
+	if t.Workers < 1 || t.Workers > 64 {
+		problems = append(problems, tr("config.bad_workers", t.Workers))
+	}
+	if t.CollectorSeconds < 1 {
+		problems = append(problems, tr("config.bad_collector_s", t.CollectorSeconds))
+	}
+	if t.TotalSeconds < t.CollectorSeconds {
+		problems = append(problems, tr("config.bad_total_s", t.TotalSeconds, t.CollectorSeconds))
+	}
+
+	if t.LockWaitSeconds < 0 {
+		problems = append(problems, tr("config.bad_lock_wait", t.LockWaitSeconds))
+	}
+	if t.LockStaleSeconds < 10 {
+		problems = append(problems, tr("config.bad_lock_stale", t.LockStaleSeconds))
+	}
+
+	if cfg.AnyDesk.SetPassword && strings.TrimSpace(cfg.AnyDesk.Password) == "" {
+		problems = append(problems, tr("config.no_password"))
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+// enabled reports whether a collector should run. anydesk_password also
+// needs anydesk.setPassword.
+func (cfg *Config) enabled(col Collector) bool {
+	if on, ok := cfg.Collection[col.Name()]; ok && !on {
+		return false
+	}
+	if col.Name() == "anydesk_password" {
+		return cfg.AnyDesk.SetPassword
+	}
+	return true
+}
+
+// collectors lists the enabled collectors; readOnly leaves out the ones that
+// change the machine (show).
+func (cfg *Config) collectors(readOnly bool) []Collector {
+	var out []Collector
+	for _, c := range registry {
+		if cfg.enabled(c) && !(readOnly && hasSideEffects(c)) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (cfg *Config) collectOptions() collectOptions {
+	return collectOptions{
+		workers:      cfg.Timeouts.Workers,
+		perCollector: time.Duration(cfg.Timeouts.CollectorSeconds) * time.Second,
+		total:        time.Duration(cfg.Timeouts.TotalSeconds) * time.Second,
+	}
+}
+
+func (cfg *Config) csvDialect() csvDialect {
+	d := csvDialect{
+		quoteAll: cfg.CSV.Quoting == quoteAll,
+		bom:      cfg.CSV.BOM,
+		sepLine:  cfg.CSV.SepLine,
+		crlf:     cfg.CSV.LineEnding == eolCRLF,
+	}
+	d.comma, _ = utf8.DecodeRuneInString(cfg.CSV.Delimiter)
+	return d
+}
+
+func (cfg *Config) sanitizer() sanitizer { return sanitizer{policy: cfg.Output.Sanitize} }
+
+type lockOptions struct{ wait, stale time.Duration }
+
+func (cfg *Config) lockOptions() lockOptions {
+	return lockOptions{
+		wait:  time.Duration(cfg.Timeouts.LockWaitSeconds) * time.Second,
+		stale: time.Duration(cfg.Timeouts.LockStaleSeconds) * time.Second,
+	}
+}
+
+func (cfg *Config) outputDir(base string) string {
+	d := strings.TrimSpace(cfg.Output.Dir)
+	if d == "" {
+		return base
+	}
+	if !filepath.IsAbs(d) {
+		d = filepath.Join(base, d)
+	}
+	return d
+}
+
+func (cfg *Config) csvPath(base string) string {
+	return filepath.Join(cfg.outputDir(base), cfg.Output.CSVFile)
+}
+
+func (cfg *Config) errLogPath(base string) string {
+	return filepath.Join(cfg.outputDir(base), cfg.Output.ErrorLog)
+}
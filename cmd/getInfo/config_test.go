@@ -0,0 +1,142 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeTestConfig(t *testing.T, base, data string) {
+	path := filepath.Join(base, filepath.FromSlash(configRel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The first run writes the defaults; keys left out of a hand-edited file
+// keep them.
+func TestLoadConfigDefaults(t *testing.T) {
+	base := t.TempDir()
+	cfg, err := loadConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, filepath.FromSlash(configRel))); err != nil {
+		t.Fatalf("defaults not written: %v", err)
+	}
+	if cfg.csvPath(base) != filepath.Join(base, CsvName) || cfg.collectOptions().total != CollectDeadline {
+		t.Errorf("defaults = %+v", cfg)
+	}
+
+	writeTestConfig(t, base, "\xEF\xBB\xBF"+`{"collection": {"ssd": false}, "output": {"dir": "saida"}, "timeouts": {"totalSeconds": 90}}`)
+	cfg, err = loadConfig(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.csvPath(base); got != filepath.Join(base, "saida", CsvName) {
+		t.Errorf("csvPath = %q", got)
+	}
+	if o := cfg.collectOptions(); o.total != 90*time.Second || o.workers != CollectWorkers {
+		t.Errorf("collectOptions = %+v", o)
+	}
+	for _, col := range cfg.collectors(false) {
+		switch col.Name() {
+		case "ssd", "anydesk_password":
+			t.Errorf("%s enabled", col.Name())
+		}
+	}
+}
+
+// Problems are reported with the key or line to fix.
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, c := range []struct{ data, want string }{
+		{`{"colection": {}}`, "colection"},
+		{"{\n  \"timeouts\": {\n    \"workers\": \"4\"\n  }\n}", "linha 3"},
+		{`{"collection": {"serial": true, "discos": true}}`, "collection.discos"},
+		{`{"timeouts": {"workers": 0, "collectorSeconds": 30, "totalSeconds": 10}}`, "timeouts.workers"},
+		{`{"timeouts": {"collectorSeconds": 30, "totalSeconds": 10}}`, "timeouts.totalSeconds"},
+		{`{"output": {"csvFile": " "}}`, "output.csvFile"},
+		{`{"anydesk": {"setPassword": true}}`, "anydesk.password"},
+	} {
+		base := t.TempDir()
+		writeTestConfig(t, base, c.data)
+		if _, err := loadConfig(base); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: err = %v, want %q", c.data, err, c.want)
+		}
+	}
+}
+
+func TestLoadConfigConcurrentFirstRun(t *testing.T) {
+	base := t.TempDir()
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := loadConfig(base); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	left, _ := filepath.Glob(filepath.Join(base, "config", ".config-*"))
+	if len(left) > 0 {
+		t.Errorf("temp files left: %v", left)
+	}
+}
+
+func TestLoadConfigTruncated(t *testing.T) {
+	base := t.TempDir()
+	path := filepath.Join(base, filepath.FromSlash(configRel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{"", "{\n  \"collection\": {\n    \"sn\": tr"} {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadConfig(base)
+		if err != nil {
+			t.Fatalf("fresh %q: %v", data, err)
+		}
+		if cfg.Output.CSVFile != CsvName {
+			t.Errorf("fresh %q: not the defaults", data)
+		}
+
+		old := time.Now().Add(-time.Hour)
+		os.Chtimes(path, old, old)
+		if _, err := loadConfig(base); err == nil {
+			t.Errorf("old %q: no error", data)
+		}
+	}
+}
+
+func TestCollectorsReadOnly(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.AnyDesk = AnyDeskConfig{SetPassword: true, Password: "x"}
+	has := func(cols []Collector) bool {
+		for _, c := range cols {
+			if c.Name() == "anydesk_password" {
+				return true
+			}
+		}
+		return false
+	}
+	if !has(cfg.collectors(false)) {
+		t.Error("collect: anydesk_password missing")
+	}
+	if has(cfg.collectors(true)) {
+		t.Error("show: anydesk_password runs")
+	}
+}
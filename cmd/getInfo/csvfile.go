@@ -3,74 +3,159 @@ package main
 import (
 	"bytes"
 	"encoding/csv"
+	"errors"
 	"os"
-	"strings"
 )
 
-const csvSepLine = "sep=,"
-
-// Creates file if needed (BOM + sep=,) and upgrades old ';' CSVs.
-func ensureCSVReady(path string, header []string) (*os.File, error) {
+// ensureCSVReady creates the file (preamble + header) if needed, and
+// converts an existing one written in another dialect (old ';' files, or a
+// changed "csv" config).
+func ensureCSVReady(path string, header []string, d csvDialect) error {
 	info, statErr := os.Stat(path)
 	if os.IsNotExist(statErr) || (statErr == nil && info.Size() == 0) {
-		f, e := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
-		if e != nil {
-			return nil, e
+		return os.WriteFile(path, d.encode(header, nil), 0644)
+	}
+
+	data, e := os.ReadFile(path)
+	if e != nil {
+		return e
+	}
+	if l := sniffCSV(data, d); !l.matches(d) {
+		if e := convertCSV(path, data, l, d); e != nil {
+			return trErr("csv.convert", e)
 		}
-		_, _ = f.Write([]byte{0xEF, 0xBB, 0xBF}) // BOM for Excel/PT-BR
-		_, _ = f.WriteString(csvSepLine + "\r\n")
-		w := csv.NewWriter(f)
-		w.UseCRLF = true
-		w.Comma = ','
-		if e := w.Write(header); e != nil {
-			f.Close()
-			return nil, e
+	}
+	return nil
+}
+
+// convertCSV rewrites a file in dialect d. The old file is parsed with its
+// own delimiter (so quoted "Sala 3; bloco B" survives), re-serialized to a
+// temp file, read back and compared, and only then swapped in; the original
+// is kept as a timestamped .bak.
+func convertCSV(path string, data []byte, l csvLayout, d csvDialect) error {
+	r := csv.NewReader(bytes.NewReader(l.body))
+	r.Comma = l.comma
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return errors.New(tr("csv.no_header"))
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, d.encode(records[0], records[1:]), 0644); err != nil {
+		return err
+	}
+	header, rows, err := readInventoryCSV(tmp, d)
+	if err == nil && !sameRecords(append([][]string{header}, rows...), records) {
+		err = errors.New(tr("csv.convert_mismatch"))
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return trErr("csv.validate", err)
+	}
+	if _, err := writeBackup(path, data); err != nil {
+		os.Remove(tmp)
+		return trErr("csv.backup", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func sameRecords(a, b [][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !sameHeader(a[i], b[i]) {
+			return false
 		}
-		w.Flush()
-		if e := w.Error(); e != nil {
-			f.Close()
-			return nil, e
+	}
+	return true
+}
+
+// appendCSVRows appends rows to an existing file, in one write.
+func appendCSVRows(path string, rows [][]string, d csvDialect) error {
+	var buf bytes.Buffer
+	for _, r := range rows {
+		if err := d.writeRow(&buf, r); err != nil {
+			return err
 		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
 		f.Close()
-		return os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+		return err
 	}
+	return f.Close()
+}
 
-	// Upgrade existing file: add "sep=," and convert ';'->',' if necessary.
-	data, e := os.ReadFile(path)
-	if e == nil {
-		content := string(data)
-		hasSep := strings.HasPrefix(strings.TrimLeft(content, "\xEF\xBB\xBF"), "sep=")
-		if !hasSep {
-			firstNL := strings.IndexByte(content, '\n')
-			firstLine := content
-			if firstNL >= 0 {
-				firstLine = strings.TrimRight(content[:firstNL], "\r\n")
-			}
-			oldComma := ','
-			if strings.Contains(firstLine, ";") && !strings.Contains(firstLine, ",") {
-				oldComma = ';'
-			}
-			conv := content
-			if oldComma == ';' {
-				conv = strings.ReplaceAll(content, ";", ",")
-			}
-			var buf bytes.Buffer
-			buf.Write([]byte{0xEF, 0xBB, 0xBF})
-			buf.WriteString(csvSepLine + "\r\n")
-			buf.WriteString(strings.TrimLeft(conv, "\xEF\xBB\xBF"))
-			_ = os.WriteFile(path, buf.Bytes(), 0644)
-		}
+// writeCSVFile rewrites a whole CSV through a temp file so a failure never
+// leaves it truncated.
+func writeCSVFile(path string, header []string, rows [][]string, d csvDialect) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, d.encode(header, rows), 0644); err != nil {
+		return err
 	}
-	return os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
 }
 
-func appendCSVRow(f *os.File, row []string) error {
-	w := csv.NewWriter(f)
-	w.UseCRLF = true
-	w.Comma = ','
-	if err := w.Write(row); err != nil {
+// csvSink is the Excel-oriented inventario.csv.
+type csvSink struct {
+	path     string
+	upsert   bool
+	identity []string // headers forming the machine key (upsert)
+	history  string   // superseded rows go here; "" = dropped
+	log      func(ctx string, err error, detail string)
+	lock     lockOptions
+	dialect  csvDialect
+	vocab    VocabularyConfig
+	clean    sanitizer
+}
+
+func (s csvSink) Name() string { return "csv" }
+
+func (s csvSink) Write(rec *InventoryRecord) error {
+	l, err := acquireLock(s.path, s.lock.wait, s.lock.stale)
+	if err != nil {
 		return err
 	}
-	w.Flush()
-	return w.Error()
+	defer l.release()
+
+	if err := ensureCSVReady(s.path, Headers(), s.dialect); err != nil {
+		return trErr("csv.prepare", s.path, err)
+	}
+	// Files from older builds may have other columns: fix the header first so
+	// the new row lines up with it.
+	fileHeader, mig, err := migrateCSVHeader(s.path, Headers(), s.dialect)
+	if err != nil {
+		return trErr("csv.migrate", s.path, err)
+	}
+	if mig != nil && s.log != nil {
+		s.log("csv_migration", errors.New(tr("csv.migrated")), mig.String())
+	}
+
+	if err := l.check(); err != nil {
+		return err
+	}
+	if s.upsert {
+		return s.upsertRow(rec)
+	}
+	row := remapRow(Row(spreadsheetValues(rec, s.clean, s.vocab)), Headers(), fileHeader)
+	if err := appendCSVRows(s.path, [][]string{row}, s.dialect); err != nil {
+		return trErr("csv.append", s.path, err)
+	}
+	return nil
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// A pre-"sep=" file written with ';' is parsed with its own delimiter, so a
+// quoted ';' inside a value survives the conversion.
+func TestUpgradeLegacyCSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CsvName)
+	old := "SN;Host;Local\r\nSN1;PC-01;\"Sala 3; bloco B\"\r\nSN2;PC-02;Recepcao, 2o andar\r\n"
+	os.WriteFile(path, []byte(old), 0644)
+
+	if err := ensureCSVReady(path, []string{"SN", "Host", "Local"}, defaultDialect); err != nil {
+		t.Fatal(err)
+	}
+	header, rows, err := readInventoryCSV(path, defaultDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"SN1", "PC-01", "Sala 3; bloco B"}, {"SN2", "PC-02", "Recepcao, 2o andar"}}
+	if !slices.Equal(header, []string{"SN", "Host", "Local"}) || !slices.EqualFunc(rows, want, slices.Equal) {
+		t.Errorf("header %q, rows %q", header, rows)
+	}
+	baks, _ := filepath.Glob(path + ".*.bak")
+	if len(baks) != 1 {
+		t.Fatalf("backups: %v", baks)
+	}
+	if data, _ := os.ReadFile(baks[0]); string(data) != old {
+		t.Errorf("backup = %q", data)
+	}
+}
+
+// A legacy file that does not parse is left alone.
+func TestUpgradeLegacyCSVInvalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, CsvName)
+	old := "SN;Host\r\nSN1;\"PC-01\r\n"
+	os.WriteFile(path, []byte(old), 0644)
+	if err := ensureCSVReady(path, []string{"SN", "Host"}, defaultDialect); err == nil {
+		t.Fatal("no error")
+	}
+	if data, _ := os.ReadFile(path); string(data) != old {
+		t.Errorf("file changed: %q", data)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(left) > 0 {
+		t.Errorf("left behind: %v", left)
+	}
+}
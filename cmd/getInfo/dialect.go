@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// csvDialect is the layout of every CSV the tool writes (inventory, history,
+// export), from the "csv" config section.
+type csvDialect struct {
+	comma    rune
+	quoteAll bool // quote every field, not only those that need it
+	bom      bool
+	sepLine  bool // "sep=X" first line, so Excel picks the delimiter
+	crlf     bool
+}
+
+// defaultDialect is what Excel (PT-BR included) opens without an import
+// wizard.
+var defaultDialect = csvDialect{comma: ',', bom: true, sepLine: true, crlf: true}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func (d csvDialect) eol() string {
+	if d.crlf {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// preamble is what comes before the header: BOM and sep line.
+func (d csvDialect) preamble() []byte {
+	var b []byte
+	if d.bom {
+		b = append(b, utf8BOM...)
+	}
+	if d.sepLine {
+		b = append(b, "sep="+string(d.comma)+d.eol()...)
+	}
+	return b
+}
+
+func (d csvDialect) writeRow(w io.Writer, row []string) error {
+	if !d.quoteAll {
+		cw := csv.NewWriter(w)
+		cw.Comma = d.comma
+		cw.UseCRLF = d.crlf
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	var b strings.Builder
+	for i, f := range row {
+		if i > 0 {
+			b.WriteRune(d.comma)
+		}
+		f = strings.ReplaceAll(f, `"`, `""`)
+		if d.crlf {
+			// Same as csv.Writer: embedded newlines follow the file's.
+			f = strings.ReplaceAll(strings.ReplaceAll(f, "\r\n", "\n"), "\n", "\r\n")
+		}
+		b.WriteString(`"` + f + `"`)
+	}
+	b.WriteString(d.eol())
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// encode renders a whole file: preamble, header, rows.
+func (d csvDialect) encode(header []string, rows [][]string) []byte {
+	var buf bytes.Buffer
+	buf.Write(d.preamble())
+	// Writes to a bytes.Buffer cannot fail.
+	_ = d.writeRow(&buf, header)
+	for _, r := range rows {
+		_ = d.writeRow(&buf, r)
+	}
+	return buf.Bytes()
+}
+
+// csvLayout is what sniffCSV found in an existing file.
+type csvLayout struct {
+	comma   rune
+	bom     bool
+	sepLine bool
+	crlf    bool
+	body    []byte // header and rows, preamble stripped
+}
+
+// sniffCSV detects how a file was written. Without a sep line the delimiter
+// is guessed from the header (column names never contain one): d's first,
+// then the usual suspects, so old ';' files are recognised.
+func sniffCSV(data []byte, d csvDialect) csvLayout {
+	l := csvLayout{comma: d.comma, body: data}
+	if bytes.HasPrefix(l.body, utf8BOM) {
+		l.bom, l.body = true, l.body[len(utf8BOM):]
+	}
+	first := l.body
+	nl := bytes.IndexByte(l.body, '\n')
+	if nl >= 0 {
+		first = l.body[:nl]
+	}
+	l.crlf = bytes.HasSuffix(first, []byte("\r"))
+	line := strings.TrimRight(string(first), "\r")
+	if sep, ok := strings.CutPrefix(line, "sep="); ok {
+		l.sepLine = true
+		if r, size := utf8.DecodeRuneInString(sep); size > 0 && size == len(sep) {
+			l.comma = r
+		}
+		if nl >= 0 {
+			l.body = l.body[nl+1:]
+		} else {
+			l.body = nil
+		}
+		return l
+	}
+	for _, r := range []rune{d.comma, ',', ';', '\t', '|'} {
+		if strings.ContainsRune(line, r) {
+			l.comma = r
+			break
+		}
+	}
+	return l
+}
+
+// matches reports whether a file in layout l can be appended to as is.
+func (l csvLayout) matches(d csvDialect) bool {
+	return l.comma == d.comma && l.bom == d.bom && l.sepLine == d.sepLine && l.crlf == d.crlf
+}
+
+// Canonical values collectors return; Vocabulary turns them into words.
+const (
+	valueYes = "yes"
+	valueNo  = "no"
+)
+
+// Default words for yes/no columns, whatever the UI language.
+const (
+	defaultYes = "Sim"
+	defaultNo  = "Nao"
+)
+
+// render applies the vocabulary to a record's values: booleans become
+// Yes/No, and typed columns (boolean or numeric) with no value become
+// Unknown. Free-text columns are left alone.
+func (v VocabularyConfig) render(vals Values) Values {
+	out := make(Values, len(vals))
+	for k, s := range vals {
+		out[k] = s
+	}
+	yes, no := v.Yes, v.No
+	if yes == "" {
+		yes, no = defaultYes, defaultNo
+	}
+	for _, f := range boolFields {
+		switch out[f.Key] {
+		case valueYes:
+			out[f.Key] = yes
+		case valueNo:
+			out[f.Key] = no
+		}
+	}
+	for _, fs := range [][]Field{boolFields, numericFields} {
+		for _, f := range fs {
+			if out[f.Key] == "" {
+				out[f.Key] = v.Unknown
+			}
+		}
+	}
+	return out
+}
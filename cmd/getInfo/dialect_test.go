@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniffCSV(t *testing.T) {
+	for _, c := range []struct {
+		data string
+		want csvLayout
+	}{
+		{"\xEF\xBB\xBFsep=,\r\nSN,Host\r\n", csvLayout{comma: ',', bom: true, sepLine: true, crlf: true}},
+		{"sep=;\nSN;Host\n", csvLayout{comma: ';', sepLine: true}},
+		{"SN;Host\r\nA;B\r\n", csvLayout{comma: ';', crlf: true}},
+		{"SN\tHost\n", csvLayout{comma: '\t'}},
+	} {
+		l := sniffCSV([]byte(c.data), defaultDialect)
+		if l.comma != c.want.comma || l.bom != c.want.bom || l.sepLine != c.want.sepLine || l.crlf != c.want.crlf {
+			t.Errorf("sniffCSV(%q) = %+v, want %+v", c.data, l, c.want)
+		}
+	}
+}
+
+func TestDialectEncode(t *testing.T) {
+	d := csvDialect{comma: ';', quoteAll: true}
+	got := string(d.encode([]string{"SN", "Local"}, [][]string{{"7XK3Q93", "Sala \"3\"\nbloco B"}}))
+	if want := "\"SN\";\"Local\"\n\"7XK3Q93\";\"Sala \"\"3\"\"\nbloco B\"\n"; got != want {
+		t.Errorf("encode = %q, want %q", got, want)
+	}
+}
+
+// Booleans use the configured words; typed columns without a value say
+// Unknown, free text stays empty.
+func TestVocabularyRender(t *testing.T) {
+	v := VocabularyConfig{Yes: "Yes", No: "No", Unknown: "?"}
+	got := v.render(Values{FieldSSD.Key: valueNo, FieldHost.Key: ""})
+	if got[FieldSSD.Key] != "No" || got[FieldRAM.Key] != "?" || got[FieldHost.Key] != "" {
+		t.Errorf("render = %v", got)
+	}
+}
+
+// Switching the configured dialect converts the existing file once.
+func TestCSVSinkDialectChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CsvName)
+	d := csvDialect{comma: ';', crlf: true}
+	s := csvSink{path: path, dialect: defaultDialect}
+	for _, host := range []string{"PC-01", "PC-02"} {
+		if err := s.Write(newRecord(Values{FieldHost.Key: host}, "run")); err != nil {
+			t.Fatal(err)
+		}
+		s.dialect = d
+	}
+	data, _ := os.ReadFile(path)
+	if l := sniffCSV(data, d); !l.matches(d) {
+		t.Errorf("layout %+v", l)
+	}
+	if _, rows, err := readInventoryCSV(path, d); err != nil || len(rows) != 2 {
+		t.Errorf("%d rows, %v", len(rows), err)
+	}
+}
+
+// Switching back and forth within a second keeps a backup of every version.
+func TestCSVSinkDialectBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CsvName)
+	s := csvSink{path: path, dialect: defaultDialect}
+	for _, d := range []csvDialect{defaultDialect, {comma: ';', crlf: true}, defaultDialect} {
+		s.dialect = d
+		if err := s.Write(newRecord(Values{FieldHost.Key: "PC-01"}, "run")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if baks, _ := filepath.Glob(path + ".*.bak"); len(baks) != 2 {
+		t.Errorf("backups: %v", baks)
+	}
+}
+
+// The data vocabulary must not follow the UI language (ui.language, --lang,
+// system locale): rows from every site share one inventario.csv.
+func TestVocabularyIgnoresLanguage(t *testing.T) {
+	defer setLanguage(langPT)
+	products := []AVProduct{{Name: "Windows Defender", State: avOn, UpToDate: true}}
+	for _, lang := range languages {
+		setLanguage(lang)
+		got := VocabularyConfig{}.render(Values{FieldSSD.Key: valueYes, FieldGPU.Key: valueNo})
+		if got[FieldSSD.Key] != "Sim" || got[FieldGPU.Key] != "Nao" {
+			t.Errorf("%s: SSD=%q GPU=%q", lang, got[FieldSSD.Key], got[FieldGPU.Key])
+		}
+		if s := avSummary(products); s != "Windows Defender (on, up to date)" {
+			t.Errorf("%s: antivirus %q", lang, s)
+		}
+		if s := avSummary([]AVProduct{}); s != "none" {
+			t.Errorf("%s: no antivirus %q", lang, s)
+		}
+	}
+	got := VocabularyConfig{Yes: "Yes", No: "No", Unknown: "n/a"}.render(Values{FieldSSD.Key: valueNo})
+	if got[FieldSSD.Key] != "No" || got[FieldGPU.Key] != "n/a" {
+		t.Errorf("configured: SSD=%q GPU=%q", got[FieldSSD.Key], got[FieldGPU.Key])
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+type check struct {
+	name string
+	ok   bool
+	info string
+}
+
+// doctor lists what would break a collect run, without collecting.
+func cmdDoctor(args []string, _ *bufio.Reader) int {
+	fs := newFlagSet("doctor", "")
+	if err := fs.Parse(args); err != nil {
+		return flagExit(err)
+	}
+	base := exeDir()
+	var checks []check
+
+	fmt.Println(versionString())
+	cfg, err := peekConfig(base)
+	if err != nil {
+		checks = append(checks, check{"config", false, err.Error()})
+		cfg = defaultConfig()
+	} else {
+		checks = append(checks, check{"config", true, filepath.Join(base, filepath.FromSlash(configRel))})
+	}
+
+	dir := cfg.outputDir(base)
+	checks = append(checks, checkWritable(tr("doctor.outdir"), dir, ""))
+	checks = append(checks, checkWritable("csv", dir, cfg.Output.CSVFile))
+	checks = append(checks, checkWritable(tr("doctor.errlog"), dir, cfg.Output.ErrorLog))
+
+	c := newCollector()
+	c.cfg = cfg
+	admin := isAdmin(c)
+	checks = append(checks, check{tr("doctor.admin"), admin || !cfg.AnyDesk.SetPassword, fmt.Sprint(admin)})
+	if p := findAnyDeskExe(); p != "" {
+		checks = append(checks, check{"anydesk", true, p})
+	} else {
+		checks = append(checks, check{"anydesk", !cfg.enabled(registryByName("anydesk_id")), ErrNotFound.Error()})
+	}
+	checks = append(checks, platformChecks(c)...)
+	checks = append(checks, doctorSMBIOS(c, admin))
+
+	failed := 0
+	for _, ck := range checks {
+		status := tr("doctor.ok")
+		if !ck.ok {
+			status = tr("doctor.fail")
+			failed++
+		}
+		fmt.Printf("[%-5s] %-16s %s\n", status, ck.name, ck.info)
+	}
+	fmt.Println(tr("doctor.summary", runtime.GOOS, runtime.GOARCH, len(checks), failed))
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// checkWritable opens an existing file for append (an open Excel lock shows
+// up here) and never creates one: a missing file or directory is checked with
+// a probe file in the nearest existing directory, removed right after.
+func checkWritable(label, dir, name string) check {
+	p := filepath.Join(dir, name)
+	if name != "" {
+		f, err := os.OpenFile(p, os.O_APPEND|os.O_WRONLY, 0)
+		if err == nil {
+			f.Close()
+			return check{label, true, p}
+		}
+		if !os.IsNotExist(err) {
+			return check{label, false, err.Error()}
+		}
+	}
+	d := dir
+	for {
+		if _, err := os.Stat(d); err == nil || filepath.Dir(d) == d {
+			break
+		}
+		d = filepath.Dir(d)
+	}
+	f, err := os.CreateTemp(d, ".getinfo_probe*")
+	if err != nil {
+		return check{label, false, err.Error()}
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return check{label, true, p}
+}
+
+// doctorSMBIOS checks the table without the GETINFO_RECORD copy a run would
+// save: doctor leaves no files behind.
+func doctorSMBIOS(c *collector, admin bool) check {
+	src := newSMBIOSSource(c.fsRoot())
+	src.save = ""
+	return checkSMBIOS(src, admin)
+}
+
+// checkSMBIOS reads the firmware table the way a run would.
+func checkSMBIOS(src *smbiosSource, admin bool) check {
+	info, err := src.get()
+	if info == nil {
+		return deniedCheck("smbios", err, admin)
+	}
+	used, total, _ := info.memorySlots()
+	msg := tr("doctor.smbios", info.major, info.minor, used, total)
+	if err != nil {
+		return check{"smbios", false, msg + "; " + err.Error()}
+	}
+	return check{"smbios", true, msg}
+}
+
+// deniedCheck reports a failed read. Without root, a permission error is
+// expected (Linux keeps the serial and the SMBIOS table root-only) and a run
+// just leaves those values to the fallbacks, so it is not a failure.
+func deniedCheck(name string, err error, admin bool) check {
+	if !admin && errors.Is(err, fs.ErrPermission) {
+		return check{name, true, tr("doctor.noroot", err)}
+	}
+	return check{name, false, err.Error()}
+}
+
+func registryByName(name string) Collector {
+	for _, c := range registry {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
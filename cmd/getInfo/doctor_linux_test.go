@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Doctor reads the firmware table but, unlike a run, never saves the
+// GETINFO_RECORD copy.
+func TestDoctorSMBIOSNoRecording(t *testing.T) {
+	raw := readTestDump(t, "desktop_v32.smbios.bin")
+	root := t.TempDir()
+	dir := filepath.Join(root, "sys", "firmware", "dmi", "tables")
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "smbios_entry_point"), []byte{'_', 'S', 'M', '3', '_', 0, 0, raw[1], raw[2]}, 0444)
+	os.WriteFile(filepath.Join(dir, "DMI"), raw[rawSMBIOSHeaderLen:], 0444)
+	rec := filepath.Join(t.TempDir(), "run.json")
+	t.Setenv(envRecord, rec)
+	t.Setenv(envSMBIOS, "")
+	t.Setenv(envReplay, "")
+
+	if ck := doctorSMBIOS(&collector{root: root}, true); !ck.ok {
+		t.Errorf("smbios: %+v", ck)
+	}
+	if _, err := os.Stat(rec + smbiosDumpSuffix); !os.IsNotExist(err) {
+		t.Errorf("dump saved: %v", err)
+	}
+}
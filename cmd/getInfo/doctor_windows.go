@@ -0,0 +1,21 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+)
+
+func platformChecks(c *collector) []check {
+	var out []check
+	for _, tool := range []string{"powershell", "wmic", "reg", "whoami"} {
+		p, err := exec.LookPath(tool)
+		// wmic is only a fallback and is gone from recent Windows 11 builds.
+		out = append(out, check{tool, err == nil || tool == "wmic", firstNonEmpty(p, ErrNotFound.Error())})
+	}
+	if o, err := c.runPS(`(Get-CimInstance Win32_BIOS).SerialNumber`); err != nil || strings.TrimSpace(o) == "" {
+		out = append(out, check{"cim/wmi", false, strings.TrimSpace(o + " " + errString(err))})
+	} else {
+		out = append(out, check{"cim/wmi", true, "Win32_BIOS ok"})
+	}
+	return out
+}
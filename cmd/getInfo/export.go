@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+func cmdExport(args []string, _ *bufio.Reader) int {
+	fs := newFlagSet("export", tr("export.args"))
+	in := fs.String("in", "", tr("export.in"))
+	out := fs.String("out", "", tr("export.out"))
+	format := fs.String("format", "json", tr("export.format"))
+	if err := fs.Parse(args); err != nil {
+		return flagExit(err)
+	}
+	switch *format {
+	case "json", "ndjson", "csv", "tsv":
+	default:
+		fmt.Fprintln(os.Stderr, tr("cli.bad_format", *format))
+		return 2
+	}
+	base := exeDir()
+	cfg, err := peekConfig(base)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("err.config", err))
+		return 1
+	}
+	src := *in
+	if src == "" {
+		src = cfg.csvPath(base)
+	}
+
+	header, rows, err := readInventoryCSV(src, cfg.csvDialect())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, tr("export.read", err))
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, tr("export.create", err))
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	if err := exportRows(bw, *format, header, rows, cfg.csvDialect()); err != nil {
+		fmt.Fprintln(os.Stderr, tr("export.write", err))
+		return 1
+	}
+	if err := bw.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, tr("export.write", err))
+		return 1
+	}
+	return 0
+}
+
+// readInventoryCSV reads a file written by ensureCSVReady: optional BOM,
+// optional "sep=X" line, then header and rows. Without a sep line the
+// delimiter is sniffed (see sniffCSV), d's first.
+func readInventoryCSV(path string, d csvDialect) (header []string, rows [][]string, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	l := sniffCSV(data, d)
+	r := csv.NewReader(bytes.NewReader(l.body))
+	r.Comma = l.comma
+	r.FieldsPerRecord = -1
+	all, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(all) == 0 {
+		return nil, nil, errors.New(tr("export.empty", path))
+	}
+	return all[0], all[1:], nil
+}
+
+func exportRows(w io.Writer, format string, header []string, rows [][]string, d csvDialect) error {
+	switch format {
+	case "csv":
+		_, err := w.Write(d.encode(header, rows))
+		return err
+	case "tsv":
+		_, err := w.Write(csvDialect{comma: '\t', crlf: d.crlf}.encode(header, rows))
+		return err
+	}
+
+	objs := make([]csvObject, len(rows))
+	for i, r := range rows {
+		objs[i] = csvObject{header, r}
+	}
+	if format == "ndjson" {
+		enc := json.NewEncoder(w)
+		for _, o := range objs {
+			if err := enc.Encode(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(objs)
+}
+
+// csvObject is one row as a JSON object with the keys in column order (a
+// map would sort them). Missing trailing cells are ""; a repeated header
+// keeps its first column.
+type csvObject struct{ header, row []string }
+
+func (o csvObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	seen := make(map[string]bool, len(o.header))
+	for i, h := range o.header {
+		if seen[h] {
+			continue
+		}
+		if len(seen) > 0 {
+			b.WriteByte(',')
+		}
+		seen[h] = true
+		v := ""
+		if i < len(o.row) {
+			v = o.row[i]
+		}
+		k, err := json.Marshal(h)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		val, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// JSON keys follow the column order, not the alphabet.
+func TestExportJSONOrder(t *testing.T) {
+	header := []string{"SN", "Host", "Data", "SN"}
+	rows := [][]string{{"7XK3Q93", "PC-01", "2026-10-18 09:30:00", "dup"}, {"ABC"}}
+	for format, want := range map[string]string{
+		"ndjson": `{"SN":"7XK3Q93","Host":"PC-01","Data":"2026-10-18 09:30:00"}` + "\n" + `{"SN":"ABC","Host":"","Data":""}` + "\n",
+		"json":   "[\n  {\n    \"SN\": \"7XK3Q93\",\n    \"Host\": \"PC-01\",\n    \"Data\": \"2026-10-18 09:30:00\"\n  },\n  {\n    \"SN\": \"ABC\",\n    \"Host\": \"\",\n    \"Data\": \"\"\n  }\n]\n",
+	} {
+		var b bytes.Buffer
+		if err := exportRows(&b, format, header, rows, defaultDialect); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != want {
+			t.Errorf("%s:\n%s\nwant\n%s", format, b.String(), want)
+		}
+	}
+}
+
+// Export reads the config like show and doctor: a missing file is not
+// written.
+func TestExportLeavesNoConfig(t *testing.T) {
+	cfgPath := filepath.Join(exeDir(), filepath.FromSlash(configRel))
+	if _, err := os.Stat(cfgPath); err == nil {
+		t.Skip("config already next to the test binary")
+	}
+	dir := t.TempDir()
+	in := filepath.Join(dir, "inventario.csv")
+	os.WriteFile(in, []byte("SN,Host\n7XK3Q93,PC-01\n"), 0644)
+	out := filepath.Join(dir, "out.json")
+	if code := cmdExport([]string{"-in", in, "-out", out}, nil); code != 0 {
+		t.Fatalf("exit %d", code)
+	}
+	if _, err := os.Stat(cfgPath); !os.IsNotExist(err) {
+		t.Errorf("config written: %v", err)
+		os.RemoveAll(filepath.Dir(cfgPath))
+	}
+}
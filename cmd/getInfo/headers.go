@@ -1,14 +1,134 @@
 package main
 
-// Short column names keep Excel clean and readable.
-// Added MGuid (MachineGuid) as requested.
-var Headers = []string{
-	"SN", "UUID", "MGuid",
-	"Patr", "Nome", "Local",
-	"Host", "User", "MSTSC",
-	"IP", "Win", "CPU",
-	"RAM_GB", "Slot_Us", "Slot_Tot", "Slot_Liv",
-	"Disk_GB", "Livre_GB", "SSD",
-	"AD_ID",
-	"Data",
+// Short column names keep Excel clean and readable. Headers are part of the
+// file format: renaming one splits old and new rows into two columns.
+var (
+	FieldSN    = Field{"sn", "SN"}
+	FieldUUID  = Field{"uuid", "UUID"}
+	FieldMGuid = Field{"mguid", "MGuid"}
+
+	FieldPatr  = Field{"patr", "Patr"}
+	FieldNome  = Field{"nome", "Nome"}
+	FieldLocal = Field{"local", "Local"}
+
+	FieldHost  = Field{"host", "Host"}
+	FieldUser  = Field{"user", "User"}
+	FieldMSTSC = Field{"mstsc", "MSTSC"}
+	FieldIP    = Field{"ip", "IP"}
+	FieldWin   = Field{"win", "Win"}
+	FieldCPU   = Field{"cpu", "CPU"}
+
+	FieldRAM     = Field{"ram_gb", "RAM_GB"}
+	FieldRAMType = Field{"ram_type", "RAM_Type"}
+	FieldSlotUs  = Field{"slot_us", "Slot_Us"}
+	FieldSlotTot = Field{"slot_tot", "Slot_Tot"}
+	FieldSlotLiv = Field{"slot_liv", "Slot_Liv"}
+
+	FieldDisk  = Field{"disk_gb", "Disk_GB"}
+	FieldLivre = Field{"livre_gb", "Livre_GB"}
+	FieldSSD   = Field{"ssd", "SSD"}
+	FieldDisks = Field{"disks", "Disks"}
+
+	FieldGPU       = Field{"gpu", "GPU"}
+	FieldGPUModel  = Field{"gpu_model", "GPU_Model"}
+	FieldGPUVRAM   = Field{"gpu_vram_gb", "GPU_VRAM_GB"}
+	FieldGPUDriver = Field{"gpu_driver", "GPU_Driver"}
+
+	FieldADID = Field{"ad_id", "AD_ID"}
+
+	FieldAV       = Field{"av", "Antivirus"}
+	FieldDefender = Field{"defender", "Defender"}
+	FieldBD       = Field{"bd_product", "BD_Product"}
+
+	FieldData = Field{"data", "Data"}
+
+	// Not collected: filled by the writer (upsert keeps First_Seen).
+	FieldFirstSeen = Field{"first_seen", "First_Seen"}
+	FieldLastSeen  = Field{"last_seen", "Last_Seen"}
+)
+
+// trackingFields always close the row, after every registered collector.
+var trackingFields = []Field{FieldFirstSeen, FieldLastSeen}
+
+// Typed columns. Numeric ones become numeric cells in the workbook; boolean
+// ones hold valueYes/valueNo (site collectors with yes/no columns add theirs
+// here). Both show vocabulary.unknown when empty.
+var (
+	numericFields = []Field{FieldRAM, FieldSlotUs, FieldSlotTot, FieldSlotLiv, FieldDisk, FieldLivre, FieldGPUVRAM}
+	boolFields    = []Field{FieldSSD, FieldGPU}
+)
+
+// Fields lists every column in CSV order.
+func Fields() []Field {
+	var fs []Field
+	for _, col := range registry {
+		fs = append(fs, col.Fields()...)
+	}
+	return append(fs, trackingFields...)
+}
+
+// Headers is the CSV header row derived from the registry.
+func Headers() []string {
+	fs := Fields()
+	h := make([]string, len(fs))
+	for i, f := range fs {
+		h[i] = f.Header
+	}
+	return h
+}
+
+// Row flattens collected values in the same order as Headers.
+func Row(vals Values) []string {
+	fs := Fields()
+	row := make([]string, len(fs))
+	for i, f := range fs {
+		row[i] = vals[f.Key]
+	}
+	return row
+}
+
+func sameHeader(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeHeader is the current header followed by any old-only columns, so
+// nothing already stored is dropped.
+func mergeHeader(old, cur []string) []string {
+	out := append([]string(nil), cur...)
+	have := map[string]bool{}
+	for _, h := range cur {
+		have[h] = true
+	}
+	for _, h := range old {
+		if !have[h] && h != "" {
+			out = append(out, h)
+			have[h] = true
+		}
+	}
+	return out
+}
+
+// remapRow moves values from the from-layout to the to-layout by column name.
+func remapRow(row, from, to []string) []string {
+	idx := make(map[string]int, len(from))
+	for i, h := range from {
+		if _, dup := idx[h]; !dup {
+			idx[h] = i
+		}
+	}
+	out := make([]string, len(to))
+	for i, h := range to {
+		if j, ok := idx[h]; ok && j < len(row) {
+			out[i] = row[j]
+		}
+	}
+	return out
 }
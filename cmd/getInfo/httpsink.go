@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// httpSink POSTs each record as JSON to a central endpoint (http section of
+// the config). It runs after the local sinks, so a network problem never
+// costs the CSV row.
+type httpSink struct {
+	cfg    HTTPConfig
+	client *http.Client // nil = built from cfg (TLS options)
+	sleep  func(time.Duration)
+}
+
+func (s httpSink) Name() string { return "http" }
+
+func (s httpSink) Write(rec *InventoryRecord) error {
+	client := s.client
+	if client == nil {
+		var err error
+		if client, err = newHTTPClient(s.cfg); err != nil {
+			return err
+		}
+	}
+	sleep := s.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	attempts := s.cfg.Retries + 1
+	var last *httpAttemptError
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			sleep(last.wait(s.backoff(i)))
+		}
+		err := s.post(client, body, rec.RunID)
+		if err == nil {
+			return nil
+		}
+		var ae *httpAttemptError
+		if !errors.As(err, &ae) || !ae.retry {
+			return err
+		}
+		last = ae
+	}
+	return trErr("http.gave_up", attempts, last)
+}
+
+// httpSpoolDir, under output.spoolDir, queues records whose upload gave up.
+const httpSpoolDir = "http"
+
+// httpRetryable reports an upload that failed on the way (network, 5xx...)
+// and may go through later; rejected ones (4xx, certificate) are not queued.
+func httpRetryable(err error) bool {
+	var ae *httpAttemptError
+	return errors.As(err, &ae) && ae.retry
+}
+
+// backoff doubles from backoffSeconds: 2s, 4s, 8s...
+func (s httpSink) backoff(retry int) time.Duration {
+	return time.Duration(s.cfg.BackoffSeconds) * time.Second << (retry - 1)
+}
+
+// httpAttemptError is one failed POST; retry tells whether trying again can
+// help (network errors, 408, 429, 5xx).
+type httpAttemptError struct {
+	err        error
+	retry      bool
+	retryAfter time.Duration // from the Retry-After header, if any
+}
+
+func (e *httpAttemptError) Error() string { return e.err.Error() }
+func (e *httpAttemptError) Unwrap() error { return e.err }
+
+func (e *httpAttemptError) wait(backoff time.Duration) time.Duration {
+	if e.retryAfter > backoff {
+		return e.retryAfter
+	}
+	return backoff
+}
+
+func (s httpSink) post(client *http.Client, body []byte, runID string) error {
+	req, err := http.NewRequest(http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "getInfo/"+version)
+	// Same key on every retry, so the server can drop duplicates.
+	req.Header.Set("Idempotency-Key", runID)
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+	for k, v := range s.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// An untrusted server certificate does not fix itself; anything else
+		// on the way (DNS, refused, timeout) may.
+		var verify *tls.CertificateVerificationError
+		return &httpAttemptError{err: err, retry: !errors.As(err, &verify)}
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	ae := &httpAttemptError{err: trErr("http.status", resp.Status, bytes.TrimSpace(snippet))}
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		ae.retry = true
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			ae.retryAfter = min(time.Duration(secs)*time.Second, time.Minute)
+		}
+	}
+	return ae
+}
+
+// newHTTPClient applies the TLS options: extra CA bundle on top of the
+// system roots, and a client certificate for mutual TLS.
+func newHTTPClient(cfg HTTPConfig) (*http.Client, error) {
+	tc := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, trErr("http.ca", cfg.CAFile, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, trErr("http.ca", cfg.CAFile, errors.New("PEM"))
+		}
+		tc.RootCAs = pool
+	}
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, trErr("http.client_cert", cfg.ClientCert, err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = tc
+	return &http.Client{
+		Transport: t,
+		Timeout:   time.Duration(cfg.TimeoutSeconds) * time.Second,
+	}, nil
+}
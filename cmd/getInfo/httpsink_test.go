@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testServer answers each POST with the next status of script (the last one
+// repeats) and keeps the run IDs it received.
+type testServer struct {
+	*httptest.Server
+	mu     sync.Mutex
+	script []int
+	runIDs []string
+	hdr    http.Header
+}
+
+func newTestServer(t *testing.T, script ...int) *testServer {
+	s := &testServer{script: script}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rec InventoryRecord
+		if err := json.NewDecoder(r.Body).Decode(&rec); err != nil || r.Method != http.MethodPost {
+			t.Errorf("%s: %v", r.Method, err)
+		}
+		s.mu.Lock()
+		code := s.script[min(len(s.runIDs), len(s.script)-1)]
+		s.runIDs = append(s.runIDs, rec.RunID)
+		s.hdr = r.Header.Clone()
+		s.mu.Unlock()
+		if code == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "5")
+		}
+		w.WriteHeader(code)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.runIDs...)
+}
+
+// testHTTPSink records the waits instead of sleeping.
+func testHTTPSink(url string, retries int, waits *[]time.Duration) httpSink {
+	return httpSink{
+		cfg:   HTTPConfig{Enabled: true, URL: url, Token: "t0k", Headers: map[string]string{"X-Site": "POA"}, TimeoutSeconds: 5, Retries: retries, BackoffSeconds: 1},
+		sleep: func(d time.Duration) { *waits = append(*waits, d) },
+	}
+}
+
+func testRecord(runID string) *InventoryRecord {
+	return newRecord(Values{FieldSN.Key: "SN1", FieldHost.Key: "PC-01"}, runID)
+}
+
+func TestHTTPSinkSuccess(t *testing.T) {
+	srv := newTestServer(t, http.StatusCreated)
+	var waits []time.Duration
+	if err := testHTTPSink(srv.URL, 3, &waits).Write(testRecord("run-1")); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.received(); len(got) != 1 || got[0] != "run-1" || len(waits) != 0 {
+		t.Errorf("received %v, waits %v", got, waits)
+	}
+	for k, want := range map[string]string{"Authorization": "Bearer t0k", "Idempotency-Key": "run-1", "X-Site": "POA", "Content-Type": "application/json"} {
+		if v := srv.hdr.Get(k); v != want {
+			t.Errorf("%s = %q, want %q", k, v, want)
+		}
+	}
+}
+
+func TestHTTPSinkRetry(t *testing.T) {
+	srv := newTestServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	var waits []time.Duration
+	if err := testHTTPSink(srv.URL, 3, &waits).Write(testRecord("run-1")); err != nil {
+		t.Fatal(err)
+	}
+	// Backoff 1s, then 2s raised to the server's Retry-After.
+	if n := len(srv.received()); n != 3 || len(waits) != 2 || waits[0] != time.Second || waits[1] != 5*time.Second {
+		t.Errorf("%d requests, waits %v", n, waits)
+	}
+}
+
+func TestHTTPSinkTimeout(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+		if first {
+			<-release
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	var waits []time.Duration
+	s := testHTTPSink(srv.URL, 1, &waits)
+	s.client = &http.Client{Timeout: 100 * time.Millisecond}
+	if err := s.Write(testRecord("run-1")); err != nil {
+		t.Fatal(err)
+	}
+	if len(waits) != 1 {
+		t.Errorf("waits %v", waits)
+	}
+}
+
+func TestHTTPSinkGiveUp(t *testing.T) {
+	srv := newTestServer(t, http.StatusBadGateway)
+	var waits []time.Duration
+	err := testHTTPSink(srv.URL, 2, &waits).Write(testRecord("run-1"))
+	if err == nil || !httpRetryable(err) {
+		t.Fatalf("err = %v", err)
+	}
+	if n := len(srv.received()); n != 3 || len(waits) != 2 || waits[1] != 2*time.Second {
+		t.Errorf("%d requests, waits %v", n, waits)
+	}
+
+	// Rejected: no retry, and not worth queuing.
+	srv = newTestServer(t, http.StatusUnauthorized)
+	waits = nil
+	err = testHTTPSink(srv.URL, 2, &waits).Write(testRecord("run-1"))
+	if err == nil || httpRetryable(err) || len(srv.received()) != 1 {
+		t.Errorf("401: err %v, %d requests", err, len(srv.received()))
+	}
+}
+
+func TestHTTPSinkSpool(t *testing.T) {
+	dir := t.TempDir()
+	srv := newTestServer(t, http.StatusServiceUnavailable)
+	var waits []time.Duration
+	spool := spoolSink{inner: testHTTPSink(srv.URL, 1, &waits), dir: dir, log: func(string, error, string) {}, queueIf: httpRetryable}
+
+	var warn sinkWarning
+	if err := spool.Write(testRecord("run-1")); !errors.As(err, &warn) {
+		t.Fatalf("err = %v, want a queued warning", err)
+	}
+	if files, _ := spool.pending(); len(files) != 1 {
+		t.Fatalf("pending = %v", files)
+	}
+
+	// Server back: the queued record goes first, then this run's.
+	srv.mu.Lock()
+	srv.script, srv.runIDs = []int{http.StatusOK}, nil
+	srv.mu.Unlock()
+	if err := spool.Write(testRecord("run-2")); err != nil {
+		t.Fatal(err)
+	}
+	if got := srv.received(); len(got) != 2 || got[0] != "run-1" || got[1] != "run-2" {
+		t.Errorf("received %v", got)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("spool not drained: %v", entries)
+	}
+
+	// Rejected records are reported, not queued.
+	srv.mu.Lock()
+	srv.script = []int{http.StatusBadRequest}
+	srv.mu.Unlock()
+	if err := spool.Write(testRecord("run-3")); err == nil || errors.As(err, &warn) {
+		t.Errorf("400: err = %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(files) != 0 {
+		t.Errorf("400 queued: %v", files)
+	}
+}
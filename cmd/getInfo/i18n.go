@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Message catalog: every operator-facing text (usage, prompts, console,
+// error log, display labels) by stable key. Collector names, field keys, CSV
+// headers and config keys are identifiers and are not translated. Text stays
+// ASCII so it survives any console code page.
+
+const (
+	langPT = "pt-BR"
+	langEN = "en"
+	langES = "es"
+)
+
+// Same order as the catalog entries.
+var languages = []string{langPT, langEN, langES}
+
+// Current language index; chosen once in main (see initLanguage).
+var langIdx = 0
+
+// key -> {pt-BR, en, es}
+var catalog = map[string][3]string{
+	// console / main
+	"err.config":         {"erro na configuracao: %v", "configuration error: %v", "error en la configuracion: %v"},
+	"err.outdir":         {"erro ao criar pasta de saida: %v", "cannot create output folder: %v", "error al crear la carpeta de salida: %v"},
+	"err.fixtures":       {"erro ao carregar fixtures: %v", "cannot load fixtures: %v", "error al cargar fixtures: %v"},
+	"err.operator_input": {"erro ao ler dados do operador: %v", "cannot read operator input: %v", "error al leer los datos del operador: %v"},
+	"err.errlog":         {"erro ao gravar log de erros: %v", "cannot write error log: %v", "error al grabar el log de errores: %v"},
+	"err.generic":        {"erro: %v", "error: %v", "error: %v"},
+	"err.not_found":      {"nao encontrado", "not found", "no encontrado"},
+	"main.collecting":    {"Coletando dados da maquina em segundo plano...", "Collecting machine data in the background...", "Recolectando datos de la maquina en segundo plano..."},
+	"sink.warning":       {"aviso na saida %s: %v", "warning on output %s: %v", "aviso en la salida %s: %v"},
+	"sink.failed":        {"erro ao gravar saida %s: %v", "cannot write output %s: %v", "error al grabar la salida %s: %v"},
+
+	// usage and flags
+	"usage.prefix":       {"uso:", "usage:", "uso:"},
+	"usage.legacy_args":  {"<patrimonio> <nome> <local>", "<asset> <name> <location>", "<patrimonio> <nombre> <ubicacion>"},
+	"usage.command_args": {"[--lang pt-BR|en|es] <comando> [opcoes]", "[--lang pt-BR|en|es] <command> [options]", "[--lang pt-BR|en|es] <comando> [opciones]"},
+	"usage.commands":     {"comandos:", "commands:", "comandos:"},
+	"usage.examples":     {"exemplos:", "examples:", "ejemplos:"},
+	"cmd.collect":        {"coleta e grava uma linha no CSV (padrao)", "collect and write one CSV row (default)", "recolecta y graba una linea en el CSV (por defecto)"},
+	"cmd.show":           {"coleta e apenas mostra os dados, sem gravar", "collect and only show the data, write nothing", "recolecta y solo muestra los datos, sin grabar"},
+	"cmd.export":         {"converte um CSV existente (json, ndjson, csv, tsv)", "convert an existing CSV (json, ndjson, csv, tsv)", "convierte un CSV existente (json, ndjson, csv, tsv)"},
+	"cmd.doctor":         {"verifica ambiente, configuracao e permissoes", "check environment, configuration and permissions", "verifica entorno, configuracion y permisos"},
+	"cmd.version":        {"mostra a versao", "show the version", "muestra la version"},
+	"cli.bad_format":     {"formato invalido: %s", "invalid format: %s", "formato invalido: %s"},
+	"collect.args":       {"[--asset A --name N --location L] [--xlsx] [--json] [--ndjson] [<patrimonio> <nome> <local...>]", "[--asset A --name N --location L] [--xlsx] [--json] [--ndjson] [<asset> <name> <location...>]", "[--asset A --name N --location L] [--xlsx] [--json] [--ndjson] [<patrimonio> <nombre> <ubicacion...>]"},
+	"collect.asset":      {"patrimonio", "asset tag", "patrimonio"},
+	"collect.name":       {"nome (pessoa/cliente/maquina)", "name (person/customer/machine)", "nombre (persona/cliente/maquina)"},
+	"collect.location":   {"local", "location", "ubicacion"},
+	"collect.xlsx":       {"grava tambem a planilha .xlsx (output.excelFile)", "also write the .xlsx workbook (output.excelFile)", "graba tambien la planilla .xlsx (output.excelFile)"},
+	"collect.json":       {"grava tambem o JSON da maquina (output.jsonDir)", "also write the machine JSON (output.jsonDir)", "graba tambien el JSON de la maquina (output.jsonDir)"},
+	"collect.ndjson":     {"acrescenta tambem a linha NDJSON (output.ndjsonFile)", "also append the NDJSON line (output.ndjsonFile)", "agrega tambien la linea NDJSON (output.ndjsonFile)"},
+	"collect.together":   {"informe --asset, --name e --location juntos", "give --asset, --name and --location together", "indique --asset, --name y --location juntos"},
+	"show.format":        {"formato de saida: text, json ou csv", "output format: text, json or csv", "formato de salida: text, json o csv"},
+	"export.args":        {"[--in inventario.csv] [--out arquivo] [--format json|ndjson|csv|tsv]", "[--in inventario.csv] [--out file] [--format json|ndjson|csv|tsv]", "[--in inventario.csv] [--out archivo] [--format json|ndjson|csv|tsv]"},
+	"export.in":          {"CSV de origem (padrao: o configurado em output.csvFile)", "source CSV (default: output.csvFile from the configuration)", "CSV de origen (por defecto: el configurado en output.csvFile)"},
+	"export.out":         {"arquivo de destino (padrao: saida padrao)", "destination file (default: standard output)", "archivo de destino (por defecto: salida estandar)"},
+	"export.format":      {"json, ndjson, csv (no formato da secao csv da configuracao) ou tsv", "json, ndjson, csv (in the dialect of the csv config section) or tsv", "json, ndjson, csv (en el formato de la seccion csv de la configuracion) o tsv"},
+	"export.read":        {"erro ao ler CSV: %v", "cannot read CSV: %v", "error al leer el CSV: %v"},
+	"export.create":      {"erro ao criar destino: %v", "cannot create destination: %v", "error al crear el destino: %v"},
+	"export.write":       {"erro ao exportar: %v", "export failed: %v", "error al exportar: %v"},
+	"export.empty":       {"%s: vazio", "%s: empty", "%s: vacio"},
+
+	// prompt
+	"prompt.asset":    {"Patrimonio", "Asset tag", "Patrimonio"},
+	"prompt.name":     {"Nome", "Name", "Nombre"},
+	"prompt.location": {"Local", "Location", "Ubicacion"},
+	"prompt.closed":   {"entrada encerrada", "input closed", "entrada cerrada"},
+	"prompt.invalid":  {"  %s invalido: %s", "  invalid %s: %s", "  %s invalido: %s"},
+	"prompt.required": {"obrigatorio", "required", "obligatorio"},
+	"prompt.too_long": {"maximo %d caracteres", "at most %d characters", "maximo %d caracteres"},
+	"prompt.control":  {"caracteres de controle nao permitidos", "control characters are not allowed", "caracteres de control no permitidos"},
+	"prompt.enter":    {"Pressione ENTER para fechar...", "Press ENTER to close...", "Presione ENTER para cerrar..."},
+
+	// collection (error log)
+	"collect.needs_admin":    {"requer administrador", "requires administrator", "requiere administrador"},
+	"collect.deadline":       {"prazo total esgotado", "overall deadline reached", "plazo total agotado"},
+	"collect.timeout":        {"tempo esgotado", "timed out", "tiempo agotado"},
+	"env.empty":              {"%s vazio", "%s empty", "%s vacio"},
+	"anydesk.not_found":      {"anydesk nao encontrado", "anydesk not found", "anydesk no encontrado"},
+	"anydesk.no_id":          {"falha ao obter id", "could not read the id", "no se pudo obtener el id"},
+	"anydesk.no_password":    {"senha ausente", "password missing", "contrasena ausente"},
+	"storage.no_system_disk": {"disco do volume do sistema nao identificado", "disk of the system volume not identified", "disco del volumen del sistema no identificado"},
+	"runner.missing":         {"fixture ausente: %s", "missing fixture: %s", "fixture ausente: %s"},
+	"runner.platform":        {"gravacao feita em %s nao pode ser reproduzida em %s", "a recording made on %s cannot be replayed on %s", "una grabacion hecha en %s no se puede reproducir en %s"},
+	"smbios.empty":           {"firmware sem tabela SMBIOS", "firmware returned no SMBIOS table", "el firmware no devolvio tabla SMBIOS"},
+	"smbios.short":           {"tabela SMBIOS curta (%d de %d bytes)", "short SMBIOS table (%d of %d bytes)", "tabla SMBIOS corta (%d de %d bytes)"},
+	"smbios.truncated":       {"estrutura SMBIOS tipo %d truncada no byte %d", "SMBIOS structure type %d truncated at byte %d", "estructura SMBIOS tipo %d truncada en el byte %d"},
+	"smbios.version":         {"versao SMBIOS nao reconhecida em %s", "unrecognized SMBIOS version in %s", "version SMBIOS no reconocida en %s"},
+
+	// outputs (error log)
+	"file.read":            {"ler %s: %w", "read %s: %w", "leer %s: %w"},
+	"csv.convert":          {"converter CSV para o formato configurado: %w", "convert CSV to the configured dialect: %w", "convertir el CSV al formato configurado: %w"},
+	"csv.no_header":        {"arquivo sem cabecalho", "file has no header", "archivo sin encabezado"},
+	"csv.convert_mismatch": {"conteudo convertido difere do original", "converted content differs from the original", "el contenido convertido difiere del original"},
+	"csv.validate":         {"validacao: %w", "validation: %w", "validacion: %w"},
+	"csv.backup":           {"backup: %w", "backup: %w", "copia de seguridad: %w"},
+	"csv.prepare":          {"preparar %s: %w", "prepare %s: %w", "preparar %s: %w"},
+	"csv.migrate":          {"migrar cabecalho de %s: %w", "migrate header of %s: %w", "migrar encabezado de %s: %w"},
+	"csv.migrated":         {"cabecalho atualizado", "header updated", "encabezado actualizado"},
+	"csv.append":           {"gravar linha em %s: %w", "append row to %s: %w", "grabar linea en %s: %w"},
+	"mig.added":            {"novas: %s", "new: %s", "nuevas: %s"},
+	"mig.kept":             {"antigas mantidas no fim: %s", "old ones kept at the end: %s", "antiguas mantenidas al final: %s"},
+	"mig.reordered":        {"colunas reordenadas", "columns reordered", "columnas reordenadas"},
+	"mig.backup":           {"backup: %s", "backup: %s", "copia de seguridad: %s"},
+	"upsert.no_identity":   {"identidade vazia", "empty identity", "identidad vacia"},
+	"upsert.appended":      {"upsert %s: %w (%s), linha acrescentada", "upsert %s: %w (%s), row appended", "upsert %s: %w (%s), linea agregada"},
+	"upsert.history":       {"historico %s: %w", "history %s: %w", "historial %s: %w"},
+	"upsert.rewrite":       {"regravar %s: %w", "rewrite %s: %w", "regrabar %s: %w"},
+	"spool.failed":         {"%v; fila local tambem falhou: %w", "%v; local queue failed too: %w", "%v; la cola local tambien fallo: %w"},
+	"spool.queued":         {"%v; linha guardada na fila (%s, %d pendente(s)), sera gravada na proxima execucao", "%v; row queued (%s, %d pending), it will be written on the next run", "%v; linea guardada en la cola (%s, %d pendiente(s)), se grabara en la proxima ejecucion"},
+	"spool.drained":        {"linha pendente gravada", "queued row written", "linea pendiente grabada"},
+	"lock.held":            {"%s bloqueado por %s", "%s locked by %s", "%s bloqueado por %s"},
+	"lock.lost":            {"bloqueio %s perdido para outro processo", "lock %s lost to another process", "bloqueo %s perdido ante otro proceso"},
+	"lock.owner":           {"pid %d em %s desde %s", "pid %d on %s since %s", "pid %d en %s desde %s"},
+	"sanitize.formula":     {"inicia como formula", "starts like a formula", "empieza como formula"},
+	"sanitize.control":     {"caracteres de controle", "control characters", "caracteres de control"},
+	"sanitize.altered":     {"coluna %s: %s, politica %s: %q -> %q", "column %s: %s, policy %s: %q -> %q", "columna %s: %s, politica %s: %q -> %q"},
+	"sink.sanitized":       {"aviso: valor ajustado para planilha: %s", "warning: value adjusted for spreadsheets: %s", "aviso: valor ajustado para planilla: %s"},
+	"http.status":          {"resposta %s: %s", "response %s: %s", "respuesta %s: %s"},
+	"http.gave_up":         {"envio desistido apos %d tentativa(s): %w", "gave up after %d attempt(s): %w", "envio abandonado tras %d intento(s): %w"},
+	"http.ca":              {"CA %s: %w", "CA %s: %w", "CA %s: %w"},
+	"http.client_cert":     {"certificado do cliente %s: %w", "client certificate %s: %w", "certificado del cliente %s: %w"},
+	"xlsx.sheet":           {"planilha %s: %w", "sheet %s: %w", "hoja %s: %w"},
+	"xlsx.err_date":        {"Data", "Date", "Fecha"},
+	"xlsx.err_run":         {"Run", "Run", "Run"},
+	"xlsx.err_host":        {"Host", "Host", "Host"},
+	"xlsx.err_error":       {"Erro", "Error", "Error"},
+
+	// config validation
+	"config.create":          {"%s: nao foi possivel criar: %w", "%s: cannot create: %w", "%s: no se pudo crear: %w"},
+	"config.line":            {"linha %d: %v", "line %d: %v", "linea %d: %v"},
+	"config.unknown_col":     {"collection.%s: coletor desconhecido (validos: %s)", "collection.%s: unknown collector (valid: %s)", "collection.%s: recolector desconocido (validos: %s)"},
+	"config.empty":           {"%s: nao pode ser vazio", "%s: must not be empty", "%s: no puede estar vacio"},
+	"config.empty_when":      {"%s: nao pode ser vazio com %s=true", "%s: must not be empty when %s=true", "%s: no puede estar vacio con %s=true"},
+	"config.identity_needed": {"output.identity: informe ao menos uma coluna com output.mode=upsert", "output.identity: give at least one column with output.mode=upsert", "output.identity: indique al menos una columna con output.mode=upsert"},
+	"config.bad_mode":        {"output.mode: %q invalido (use append ou upsert)", "output.mode: invalid %q (use append or upsert)", "output.mode: %q invalido (use append o upsert)"},
+	"config.bad_identity":    {"output.identity: coluna %q desconhecida (ex.: SN, UUID, MGuid)", "output.identity: unknown column %q (e.g. SN, UUID, MGuid)", "output.identity: columna %q desconocida (ej.: SN, UUID, MGuid)"},
+	"config.xlsx_ext":        {"output.excelFile: deve terminar em .xlsx com output.excel=true", "output.excelFile: must end in .xlsx when output.excel=true", "output.excelFile: debe terminar en .xlsx con output.excel=true"},
+	"config.bad_sanitize":    {"output.sanitize: %q invalido (use prefix, strip ou reject)", "output.sanitize: invalid %q (use prefix, strip or reject)", "output.sanitize: %q invalido (use prefix, strip o reject)"},
+	"config.bad_url":         {"http.url: %q invalido (use http:// ou https://)", "http.url: invalid %q (use http:// or https://)", "http.url: %q invalido (use http:// o https://)"},
+	"config.client_pair":     {"http.clientCert e http.clientKey devem ser informados juntos", "http.clientCert and http.clientKey must be given together", "http.clientCert y http.clientKey deben indicarse juntos"},
+	"config.bad_positive":    {"%s: deve ser maior que 0 (atual %d)", "%s: must be greater than 0 (now %d)", "%s: debe ser mayor que 0 (actual %d)"},
+	"config.bad_negative":    {"%s: nao pode ser negativo (atual %d)", "%s: must not be negative (now %d)", "%s: no puede ser negativo (actual %d)"},
+	"config.bad_retries":     {"http.retries: deve estar entre 0 e 10 (atual %d)", "http.retries: must be between 0 and 10 (now %d)", "http.retries: debe estar entre 0 y 10 (actual %d)"},
+	"config.no_output":       {"output: nenhuma saida habilitada (csv, excel, json, ndjson)", "output: no output enabled (csv, excel, json, ndjson)", "output: ninguna salida habilitada (csv, excel, json, ndjson)"},
+	"config.bad_delimiter":   {"csv.delimiter: %q invalido (use um caractere, ex.: \",\" \";\" \"\\t\")", "csv.delimiter: invalid %q (use one character, e.g. \",\" \";\" \"\\t\")", "csv.delimiter: %q invalido (use un caracter, ej.: \",\" \";\" \"\\t\")"},
+	"config.bad_quoting":     {"csv.quoting: %q invalido (use minimal ou all)", "csv.quoting: invalid %q (use minimal or all)", "csv.quoting: %q invalido (use minimal o all)"},
+	"config.bad_eol":         {"csv.lineEnding: %q invalido (use crlf ou lf)", "csv.lineEnding: invalid %q (use crlf or lf)", "csv.lineEnding: %q invalido (use crlf o lf)"},
+	"config.bad_vocabulary":  {"vocabulary: yes e no devem ser ambos vazios (Sim/Nao) ou preenchidos e diferentes", "vocabulary: yes and no must both be empty (Sim/Nao) or set and different", "vocabulary: yes y no deben estar ambos vacios (Sim/Nao) o completos y distintos"},
+	"config.bad_language":    {"ui.language: %q invalido (use pt-BR, en, es ou vazio para o idioma do sistema)", "ui.language: invalid %q (use pt-BR, en, es or empty for the system language)", "ui.language: %q invalido (use pt-BR, en, es o vacio para el idioma del sistema)"},
+	"config.bad_workers":     {"timeouts.workers: deve estar entre 1 e 64 (atual %d)", "timeouts.workers: must be between 1 and 64 (now %d)", "timeouts.workers: debe estar entre 1 y 64 (actual %d)"},
+	"config.bad_collector_s": {"timeouts.collectorSeconds: deve ser maior que 0 (atual %d)", "timeouts.collectorSeconds: must be greater than 0 (now %d)", "timeouts.collectorSeconds: debe ser mayor que 0 (actual %d)"},
+	"config.bad_total_s":     {"timeouts.totalSeconds: deve ser >= collectorSeconds (atual %d < %d)", "timeouts.totalSeconds: must be >= collectorSeconds (now %d < %d)", "timeouts.totalSeconds: debe ser >= collectorSeconds (actual %d < %d)"},
+	"config.bad_lock_wait":   {"timeouts.lockWaitSeconds: nao pode ser negativo (atual %d)", "timeouts.lockWaitSeconds: must not be negative (now %d)", "timeouts.lockWaitSeconds: no puede ser negativo (actual %d)"},
+	"config.bad_lock_stale":  {"timeouts.lockStaleSeconds: minimo 10 (atual %d)", "timeouts.lockStaleSeconds: at least 10 (now %d)", "timeouts.lockStaleSeconds: minimo 10 (actual %d)"},
+	"config.no_password":     {"anydesk.password: vazio com anydesk.setPassword=true", "anydesk.password: empty while anydesk.setPassword=true", "anydesk.password: vacio con anydesk.setPassword=true"},
+
+	// doctor
+	"doctor.outdir":   {"pasta de saida", "output folder", "carpeta de salida"},
+	"doctor.errlog":   {"log de erros", "error log", "log de errores"},
+	"doctor.admin":    {"administrador", "administrator", "administrador"},
+	"doctor.smbios":   {"versao %d.%d, %d de %d slots de memoria ocupados", "version %d.%d, %d of %d memory slots in use", "version %d.%d, %d de %d ranuras de memoria ocupadas"},
+	"doctor.ok":       {"OK", "OK", "OK"},
+	"doctor.fail":     {"FALHA", "FAIL", "FALLA"},
+	"doctor.noroot":   {"precisa de root (%v)", "needs root (%v)", "requiere root (%v)"},
+	"summary.ok":      {"OK", "OK", "OK"},
+	"summary.warning": {"OK com aviso", "OK with warning", "OK con aviso"},
+	"summary.failed":  {"FALHA", "FAILED", "FALLA"},
+	"doctor.summary":  {"%s/%s, %d verificacoes, %d falhas", "%s/%s, %d checks, %d failed", "%s/%s, %d verificaciones, %d fallas"},
+
+	// display labels (console summary); CSV/XLSX headers stay Field.Header
+	"field.sn":          {"Numero de serie", "Serial number", "Numero de serie"},
+	"field.uuid":        {"UUID", "UUID", "UUID"},
+	"field.mguid":       {"MachineGuid", "MachineGuid", "MachineGuid"},
+	"field.patr":        {"Patrimonio", "Asset tag", "Patrimonio"},
+	"field.nome":        {"Nome", "Name", "Nombre"},
+	"field.local":       {"Local", "Location", "Ubicacion"},
+	"field.host":        {"Computador", "Computer", "Equipo"},
+	"field.user":        {"Usuario", "User", "Usuario"},
+	"field.mstsc":       {"Usuario RDP", "RDP user", "Usuario RDP"},
+	"field.ip":          {"IP", "IP", "IP"},
+	"field.win":         {"Sistema", "Operating system", "Sistema operativo"},
+	"field.cpu":         {"CPU", "CPU", "CPU"},
+	"field.ram_gb":      {"RAM (GB)", "RAM (GB)", "RAM (GB)"},
+	"field.ram_type":    {"Memoria", "Memory", "Memoria"},
+	"field.slot_us":     {"Slots usados", "Slots used", "Ranuras usadas"},
+	"field.slot_tot":    {"Slots total", "Slots total", "Ranuras totales"},
+	"field.slot_liv":    {"Slots livres", "Slots free", "Ranuras libres"},
+	"field.disk_gb":     {"Disco (GB)", "Disk (GB)", "Disco (GB)"},
+	"field.livre_gb":    {"Livre (GB)", "Free (GB)", "Libre (GB)"},
+	"field.ssd":         {"SSD", "SSD", "SSD"},
+	"field.disks":       {"Discos", "Disks", "Discos"},
+	"field.gpu":         {"Placa de video", "Graphics card", "Tarjeta de video"},
+	"field.gpu_model":   {"Modelo de video", "Graphics model", "Modelo de video"},
+	"field.gpu_vram_gb": {"Memoria de video (GB)", "Video memory (GB)", "Memoria de video (GB)"},
+	"field.gpu_driver":  {"Driver de video", "Graphics driver", "Controlador de video"},
+	"field.ad_id":       {"AnyDesk ID", "AnyDesk ID", "AnyDesk ID"},
+	"field.av":          {"Antivirus", "Antivirus", "Antivirus"},
+	"field.defender":    {"Defender", "Defender", "Defender"},
+	"field.bd_product":  {"Bitdefender", "Bitdefender", "Bitdefender"},
+	"field.data":        {"Data", "Date", "Fecha"},
+	"field.first_seen":  {"Primeira coleta", "First seen", "Primera recoleccion"},
+	"field.last_seen":   {"Ultima coleta", "Last seen", "Ultima recoleccion"},
+}
+
+// lookup returns the text for key in the current language, falling back to
+// pt-BR and then to the key itself.
+func lookup(key string) string {
+	e, ok := catalog[key]
+	if !ok {
+		return key
+	}
+	if s := e[langIdx]; s != "" {
+		return s
+	}
+	return e[0]
+}
+
+// tr formats a catalog message.
+func tr(key string, args ...any) string {
+	if len(args) == 0 {
+		return lookup(key)
+	}
+	return fmt.Sprintf(lookup(key), args...)
+}
+
+// trErr is tr for errors; %w in the message wraps like fmt.Errorf.
+func trErr(key string, args ...any) error {
+	return fmt.Errorf(lookup(key), args...)
+}
+
+// msgError is a sentinel error translated when printed, so package-level
+// errors follow the language chosen at startup and still work with errors.Is.
+type msgError string
+
+func (e msgError) Error() string { return lookup(string(e)) }
+
+// Label is the field's display name in the current language; columns from
+// site collectors without a catalog entry show their header.
+func (f Field) Label() string {
+	if _, ok := catalog["field."+f.Key]; ok {
+		return lookup("field." + f.Key)
+	}
+	return f.Header
+}
+
+// normalizeLanguage maps "pt", "pt_BR.UTF-8", "en-US", "es_MX"... to a
+// catalog language.
+func normalizeLanguage(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s, _, _ = strings.Cut(s, ".")
+	switch {
+	case s == "":
+		return "", false
+	case strings.HasPrefix(s, "pt"):
+		return langPT, true
+	case strings.HasPrefix(s, "en"):
+		return langEN, true
+	case strings.HasPrefix(s, "es"):
+		return langES, true
+	}
+	return "", false
+}
+
+func setLanguage(name string) bool {
+	l, ok := normalizeLanguage(name)
+	if !ok {
+		return false
+	}
+	for i, x := range languages {
+		if x == l {
+			langIdx = i
+		}
+	}
+	return true
+}
+
+// initLanguage picks the language before anything is printed: --lang (which
+// it removes from args), then ui.language from config.json, then the system
+// locale, then pt-BR.
+func initLanguage(args []string) []string {
+	var flagLang string
+	for len(args) > 0 {
+		if v, ok := strings.CutPrefix(args[0], "--lang="); ok {
+			flagLang, args = v, args[1:]
+		} else if args[0] == "--lang" && len(args) > 1 {
+			flagLang, args = args[1], args[2:]
+		} else {
+			break
+		}
+	}
+	for _, l := range []string{flagLang, configLanguage(exeDir()), systemLanguage()} {
+		if setLanguage(l) {
+			return args
+		}
+	}
+	langIdx = 0
+	return args
+}
+
+// configLanguage peeks at ui.language without validating the rest, so even
+// configuration errors are reported in the configured language.
+func configLanguage(base string) string {
+	data, err := os.ReadFile(filepath.Join(base, filepath.FromSlash(configRel)))
+	if err != nil {
+		return ""
+	}
+	var peek struct {
+		UI struct {
+			Language string `json:"language"`
+		} `json:"ui"`
+	}
+	_ = json.Unmarshal(bytes.TrimPrefix(data, utf8BOM), &peek)
+	return peek.UI.Language
+}
@@ -0,0 +1,14 @@
+package main
+
+import "os"
+
+// systemLanguage follows the usual POSIX precedence; "C"/"POSIX" match no
+// catalog language.
+func systemLanguage() string {
+	for _, k := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
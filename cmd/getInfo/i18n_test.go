@@ -0,0 +1,61 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+var verbRe = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// Every key has text in all languages with the same format verbs, in ASCII.
+func TestCatalogComplete(t *testing.T) {
+	for key, msgs := range catalog {
+		want := verbRe.FindAllString(msgs[0], -1)
+		for i, m := range msgs {
+			if m == "" {
+				t.Errorf("%s: no %s text", key, languages[i])
+			}
+			if got := verbRe.FindAllString(m, -1); !slices.Equal(got, want) {
+				t.Errorf("%s/%s: verbs %q, want %q", key, languages[i], got, want)
+			}
+			for _, r := range m {
+				if r > 0x7E {
+					t.Errorf("%s/%s: non-ASCII %q", key, languages[i], m)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestSetLanguage(t *testing.T) {
+	defer func() { langIdx = 0 }()
+	for in, want := range map[string]string{"pt": langPT, "pt_BR.UTF-8": langPT, "en-US": langEN, "es_MX": langES} {
+		if !setLanguage(in) || languages[langIdx] != want {
+			t.Errorf("setLanguage(%q) -> %s, want %s", in, languages[langIdx], want)
+		}
+	}
+	for _, in := range []string{"", "C", "POSIX", "de_DE"} {
+		if setLanguage(in) {
+			t.Errorf("setLanguage(%q) accepted", in)
+		}
+	}
+	setLanguage("en")
+	if got := tr("err.not_found"); got != "not found" {
+		t.Errorf("tr = %q", got)
+	}
+}
+
+// --lang wins and is removed from the arguments; config.json comes next.
+func TestInitLanguage(t *testing.T) {
+	defer func() { langIdx = 0 }()
+	if args := initLanguage([]string{"--lang=es", "show"}); len(args) != 1 || args[0] != "show" || languages[langIdx] != langES {
+		t.Errorf("args = %q, lang = %s", args, languages[langIdx])
+	}
+	base := t.TempDir()
+	writeTestConfig(t, base, `{"ui": {"language": "en"}}`)
+	if got := configLanguage(base); got != "en" {
+		t.Errorf("configLanguage = %q", got)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetUserDefaultLocaleName = syscall.NewLazyDLL("kernel32.dll").NewProc("GetUserDefaultLocaleName")
+
+// systemLanguage is the user's locale name, e.g. "pt-BR".
+func systemLanguage() string {
+	const localeNameMaxLength = 85
+	var buf [localeNameMaxLength]uint16
+	n, _, _ := procGetUserDefaultLocaleName.Call(uintptr(unsafe.Pointer(&buf[0])), localeNameMaxLength)
+	if n == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf[:])
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The address of the interface holding the default route in /proc/net/route.
+// The interface lookup is a fact: a replay reports the recorded machine's
+// address.
+func linuxGatewayIPv4(c *collector) string {
+	iface, err := readDefaultRouteIface(c.fsRoot())
+	if err != nil {
+		return ""
+	}
+	return c.fact("ip_iface:"+iface, func() string { return ifaceIPv4(iface) })
+}
+
+func ifaceIPv4(name string) string {
+	in, err := net.InterfaceByName(name)
+	if err != nil {
+		return ""
+	}
+	addrs, _ := in.Addrs()
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok {
+			if v4 := n.IP.To4(); v4 != nil {
+				return v4.String()
+			}
+		}
+	}
+	return ""
+}
+
+// readDefaultRouteIface names the interface of the first default route.
+func readDefaultRouteIface(root string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "proc", "net", "route"))
+	if err != nil {
+		return "", err
+	}
+	for _, ln := range strings.Split(string(data), "\n")[1:] {
+		fs := strings.Fields(ln)
+		// Iface Destination Gateway ...; default route has destination 0.
+		if len(fs) >= 3 && fs[1] == "00000000" {
+			return fs[0], nil
+		}
+	}
+	return "", ErrNotFound
+}
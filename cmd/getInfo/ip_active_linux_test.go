@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestReadDefaultRouteIface(t *testing.T) {
+	if iface, err := readDefaultRouteIface(desktopRoot); iface != "enp3s0" || err != nil {
+		t.Errorf("iface = %q, %v", iface, err)
+	}
+	if _, err := readDefaultRouteIface(armRoot); err == nil {
+		t.Error("no default route: no error")
+	}
+}
+
+// The route file and the interface address both come from the recording.
+func TestLinuxGatewayIPv4Replay(t *testing.T) {
+	rr := &replayRunner{facts: map[string]string{"ip_iface:enp3s0": "192.168.1.20"}}
+	c := &collector{runner: rr, root: desktopRoot}
+	if ip := linuxGatewayIPv4(c); ip != "192.168.1.20" {
+		t.Errorf("ip = %q", ip)
+	}
+	c.root = armRoot
+	if ip := linuxGatewayIPv4(c); ip != "" {
+		t.Errorf("no default route: ip = %q", ip)
+	}
+}
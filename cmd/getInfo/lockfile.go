@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Advisory lock for files shared by several running copies (USB stick,
+// network share): <file>.lock is created exclusively and holds the owner's
+// PID, host, start time and a random token. Every writer takes it around its
+// whole read/prepare/append sequence. While held, the lock's mtime is
+// refreshed every stale/4, so only a lock whose owner stopped refreshing it
+// looks stale.
+type fileLock struct {
+	path    string
+	content []byte
+	token   string
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+type lockOwner struct {
+	pid   int
+	host  string
+	at    time.Time
+	token string
+}
+
+func (o lockOwner) String() string {
+	return tr("lock.owner", o.pid, o.host, o.at.Format(dateLayout))
+}
+
+func parseLockOwner(data []byte) lockOwner {
+	var o lockOwner
+	for _, ln := range strings.Split(string(data), "\n") {
+		k, v, _ := strings.Cut(strings.TrimSpace(ln), "=")
+		switch k {
+		case "pid":
+			o.pid, _ = strconv.Atoi(v)
+		case "host":
+			o.host = v
+		case "time":
+			o.at, _ = time.Parse(time.RFC3339Nano, v)
+		case "token":
+			o.token = v
+		}
+	}
+	return o
+}
+
+// lockSettle is how long a lock taken right after breaking a stale one is
+// left alone before its token is checked: a process that was breaking the
+// same stale lock may have moved ours aside meanwhile.
+const lockSettle = 250 * time.Millisecond
+
+// acquireLock waits up to wait for target's lock. A lock is stale when it was
+// not refreshed for stale, or when its owner ran on this host and is gone.
+func acquireLock(target string, wait, stale time.Duration) (*fileLock, error) {
+	lockPath := target + ".lock"
+	host, _ := os.Hostname()
+	deadline := time.Now().Add(wait)
+	broke := false
+	for {
+		token := fmt.Sprintf("%016x", rand.Uint64())
+		content := []byte(fmt.Sprintf("pid=%d\nhost=%s\ntime=%s\ntoken=%s\n", os.Getpid(), host, time.Now().Format(time.RFC3339Nano), token))
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, werr := f.Write(content)
+			cerr := f.Close()
+			if werr != nil || cerr != nil {
+				os.Remove(lockPath)
+				return nil, fmt.Errorf("lock %s: %v", lockPath, firstNonNil(werr, cerr))
+			}
+			l := &fileLock{path: lockPath, content: content, token: token}
+			if broke {
+				time.Sleep(lockSettle)
+				if !l.held() {
+					broke = false
+					continue // taken over while it settled; wait our turn again
+				}
+			}
+			l.heartbeat(stale / 4)
+			return l, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("lock %s: %w", lockPath, err)
+		}
+
+		data, rerr := os.ReadFile(lockPath)
+		if rerr != nil {
+			continue // released between our create and read
+		}
+		owner := parseLockOwner(data)
+		if lockIsStale(lockPath, owner, host, stale) {
+			breakStaleLock(lockPath, data)
+			broke = true
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New(tr("lock.held", target, owner))
+		}
+		time.Sleep(time.Duration(50+rand.IntN(150)) * time.Millisecond)
+	}
+}
+
+func lockIsStale(lockPath string, o lockOwner, host string, stale time.Duration) bool {
+	// The mtime is the last heartbeat; the start time only stands in when
+	// the file cannot be stat'ed.
+	at := o.at
+	if st, err := os.Stat(lockPath); err == nil {
+		at = st.ModTime()
+	}
+	if !at.IsZero() && time.Since(at) > stale {
+		return true
+	}
+	return o.pid > 0 && strings.EqualFold(o.host, host) && !processAlive(o.pid)
+}
+
+// breakStaleLock moves the lock aside before deleting it, and puts it back
+// if another process had replaced it with a fresh one in the meantime. The
+// put-back cannot overwrite a lock created since; the owner of the one moved
+// aside then finds its token gone (see held).
+func breakStaleLock(lockPath string, seen []byte) {
+	aside := fmt.Sprintf("%s.stale.%d.%x", lockPath, os.Getpid(), rand.Uint32())
+	if err := os.Rename(lockPath, aside); err != nil {
+		return
+	}
+	if data, err := os.ReadFile(aside); err == nil && !bytes.Equal(data, seen) {
+		if err := os.Link(aside, lockPath); err != nil && !errors.Is(err, fs.ErrExist) {
+			// No hard links (FAT): a plain rename, racing as before.
+			if _, err := os.Stat(lockPath); os.IsNotExist(err) {
+				_ = os.Rename(aside, lockPath)
+				return
+			}
+		}
+	}
+	os.Remove(aside)
+}
+
+// held reports whether the lock file still carries our token.
+func (l *fileLock) held() bool {
+	data, err := os.ReadFile(l.path)
+	return err == nil && parseLockOwner(data).token == l.token
+}
+
+// check is called before committing a write: the lock must still be ours.
+func (l *fileLock) check() error {
+	if !l.held() {
+		return errors.New(tr("lock.lost", l.path))
+	}
+	return nil
+}
+
+// heartbeat touches the lock every interval until release, so a long write
+// (slow share, big workbook) is not mistaken for a crashed run.
+func (l *fileLock) heartbeat(interval time.Duration) {
+	l.stop, l.done = make(chan struct{}), make(chan struct{})
+	if interval <= 0 {
+		close(l.done)
+		return
+	}
+	go func() {
+		defer close(l.done)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-t.C:
+				if l.held() {
+					now := time.Now()
+					_ = os.Chtimes(l.path, now, now)
+				}
+			}
+		}
+	}()
+}
+
+// release stops the heartbeat and removes the lock only if it is still ours.
+func (l *fileLock) release() {
+	if l.stop != nil {
+		close(l.stop)
+		<-l.done
+	}
+	if l.held() {
+		os.Remove(l.path)
+	}
+}
+
+func firstNonNil(errs ...error) error {
+	for _, e := range errs {
+		if e != nil {
+			return e
+		}
+	}
+	return nil
+}
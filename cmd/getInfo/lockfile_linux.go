@@ -0,0 +1,9 @@
+package main
+
+import "syscall"
+
+// Signal 0 checks existence; EPERM means it exists but is not ours.
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	envLockWriter = "GETINFO_TEST_LOCK_WRITER" // target file; set for the child processes
+	lockWriters   = 8
+	lockRounds    = 5
+	lockStale     = 500 * time.Millisecond
+)
+
+// TestLockWriters starts separate processes that increment a shared counter
+// under the lock. They all begin by breaking the same stale lock, and the
+// first writer holds the lock for several stale periods, so a broken fresh
+// lock or a missing heartbeat shows up as a lost increment or an overlap.
+func TestLockWriters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns processes")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "inv.csv")
+	os.WriteFile(target, []byte("0"), 0644)
+	lock := target + ".lock"
+	os.WriteFile(lock, []byte("pid=1\nhost=crashed-pc\ntime=2020-01-01T00:00:00Z\n"), 0644)
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(lock, old, old)
+
+	var wg sync.WaitGroup
+	outs := make([][]byte, lockWriters)
+	errs := make([]error, lockWriters)
+	for i := range lockWriters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestLockWriterProcess$")
+			cmd.Env = append(os.Environ(), envLockWriter+"="+target, "GETINFO_TEST_LOCK_ID="+strconv.Itoa(i))
+			outs[i], errs[i] = cmd.CombinedOutput()
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("writer %d: %v\n%s", i, err, outs[i])
+		}
+	}
+	data, _ := os.ReadFile(target)
+	if n, _ := strconv.Atoi(string(data)); n != lockWriters*lockRounds {
+		t.Errorf("counter = %s, want %d", data, lockWriters*lockRounds)
+	}
+	if left, _ := filepath.Glob(filepath.Join(dir, "*.lock*")); len(left) > 0 {
+		t.Errorf("left behind: %v", left)
+	}
+}
+
+// TestLockWriterProcess is the child side of TestLockWriters.
+func TestLockWriterProcess(t *testing.T) {
+	target := os.Getenv(envLockWriter)
+	if target == "" {
+		t.Skip("child of TestLockWriters")
+	}
+	inside := target + ".inside"
+	for round := range lockRounds {
+		l, err := acquireLock(target, time.Minute, lockStale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Nobody else may be between acquire and release.
+		f, err := os.OpenFile(inside, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatalf("round %d: overlap: %v", round, err)
+		}
+		f.Close()
+		data, _ := os.ReadFile(target)
+		n, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		if round == 0 && os.Getenv("GETINFO_TEST_LOCK_ID") == "0" {
+			time.Sleep(3 * lockStale)
+		} else {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if err := l.check(); err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(target, []byte(fmt.Sprint(n+1)), 0644)
+		os.Remove(inside)
+		l.release()
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockExclusive(t *testing.T) {
+	target := filepath.Join(t.TempDir(), CsvName)
+	l, err := acquireLock(target, time.Second, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := acquireLock(target, 100*time.Millisecond, time.Minute); err == nil || !strings.Contains(err.Error(), fmt.Sprintf("pid %d", os.Getpid())) {
+		t.Fatalf("second writer: %v", err)
+	}
+	l.release()
+	l2, err := acquireLock(target, 100*time.Millisecond, time.Minute)
+	if err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	// A release by someone who lost the lock leaves the new owner's file.
+	(&fileLock{path: l.path, token: l.token}).release()
+	if _, err := os.Stat(l2.path); err != nil {
+		t.Errorf("new owner's lock removed: %v", err)
+	}
+	l2.release()
+}
+
+// Locks left by a crash are taken over: not refreshed for too long, or owned
+// by a process of this host that no longer exists.
+func TestLockStale(t *testing.T) {
+	host, _ := os.Hostname()
+	gone := exec.Command(os.Args[0], "-test.run=^$")
+	if err := gone.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"old":  "pid=1\nhost=outro-pc\ntime=2020-01-01T00:00:00Z\n",
+		"dead": fmt.Sprintf("pid=%d\nhost=%s\ntime=%s\n", gone.Process.Pid, host, time.Now().Format(time.RFC3339Nano)),
+	} {
+		target := filepath.Join(t.TempDir(), CsvName)
+		os.WriteFile(target+".lock", []byte(content), 0644)
+		if name == "old" {
+			old := time.Now().Add(-time.Hour)
+			os.Chtimes(target+".lock", old, old)
+		}
+		l, err := acquireLock(target, 200*time.Millisecond, time.Minute)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		l.release()
+	}
+}
+
+// Writers that overlap wait for each other instead of losing rows.
+func TestCSVSinkConcurrent(t *testing.T) {
+	s := csvSink{path: filepath.Join(t.TempDir(), CsvName), dialect: defaultDialect, lock: lockOptions{wait: 10 * time.Second, stale: time.Minute}}
+	errs := make(chan error)
+	for i := range 8 {
+		go func() {
+			errs <- s.Write(newRecord(Values{FieldHost.Key: fmt.Sprint("PC-", i)}, "run"))
+		}()
+	}
+	for range 8 {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if _, rows, err := readInventoryCSV(s.path, defaultDialect); err != nil || len(rows) != 8 {
+		t.Errorf("%d rows, %v", len(rows), err)
+	}
+}
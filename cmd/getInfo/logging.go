@@ -1,14 +1,60 @@
 package main
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 )
 
-// Used across files to collect non-fatal errors.
-type collector struct{ errs []string }
+// Per-run state shared by collectors: command runner, operator input, run
+// time and non-fatal errors. Collectors run concurrently, each on its own
+// shallow copy (see withContext) logging into its own errLog, merged into
+// the run's when it returns (see runOne).
+type collector struct {
+	log    *errLog
+	ctx    context.Context
+	runner Runner
+	runID  string
+	cfg    *Config
+	in     operatorInput
+	now    time.Time
+	root   string    // filesystem root for Linux readers ("" = "/")
+	plat   *platform // nil = this machine's (see platformFor)
+
+	smbiosSrc *smbiosSource // shared by copies; nil = no SMBIOS
+	storage   *storageCache // disks and volumes, shared by copies
+}
+
+type errLog struct {
+	mu   sync.Mutex
+	errs []string
+}
+
+func newCollector() *collector {
+	c := &collector{log: &errLog{}, ctx: context.Background(), runID: newRunID(), now: time.Now(), cfg: defaultConfig()}
+	c.storage = &storageCache{}
+	return c
+}
+
+// withContext returns a copy bound to ctx; commands it runs are killed when
+// ctx ends. Errors still land in the shared log.
+func (c *collector) withContext(ctx context.Context) *collector {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+func (c *collector) fsRoot() string {
+	if c.root == "" {
+		return "/"
+	}
+	return c.root
+}
+
+// Values typed by the operator (CLI args or interactive prompt).
+type operatorInput struct{ patr, nome, local string }
 
 func (c *collector) addErr(ctx string, err error, detail string) {
 	if err == nil {
@@ -18,7 +64,21 @@ func (c *collector) addErr(ctx string, err error, detail string) {
 	if detail != "" {
 		msg += " | " + detail
 	}
-	c.errs = append(c.errs, time.Now().Format("2006-01-02T15:04:05-0700")+" "+msg)
+	line := time.Now().Format("2006-01-02T15:04:05-0700") + " " + msg
+	c.log.append([]string{line})
+}
+
+func (l *errLog) append(lines []string) {
+	l.mu.Lock()
+	l.errs = append(l.errs, lines...)
+	l.mu.Unlock()
+}
+
+// errors returns a snapshot of the logged lines.
+func (c *collector) errors() []string {
+	c.log.mu.Lock()
+	defer c.log.mu.Unlock()
+	return append([]string(nil), c.log.errs...)
 }
 
 // Single place to persist errors (Portuguese filename kept for operators).
@@ -29,7 +89,7 @@ func writeErrors(path string, errs []string) {
 	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		// minimal console output only if log can't be written
-		fmt.Fprintln(os.Stderr, "erro ao gravar log de erros:", err)
+		fmt.Fprintln(os.Stderr, tr("err.errlog", err))
 		return
 	}
 	defer f.Close()
@@ -39,4 +99,4 @@ func writeErrors(path string, errs []string) {
 }
 
 // Shared sentinel error for "not found" cases.
-var ErrNotFound = errors.New("nao encontrado")
+var ErrNotFound error = msgError("err.not_found")
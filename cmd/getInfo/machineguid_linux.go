@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// machine-id plays the MachineGuid role: unique per installation.
+func readMachineID(root string) (string, error) {
+	var last error
+	for _, p := range []string{"etc/machine-id", "var/lib/dbus/machine-id"} {
+		b, err := os.ReadFile(filepath.Join(root, p))
+		if err != nil {
+			last = err
+			continue
+		}
+		if v := strings.TrimSpace(string(b)); v != "" {
+			return v, nil
+		}
+	}
+	if last == nil {
+		last = ErrNotFound
+	}
+	return "", last
+}
+
+func linuxMachineGuid(c *collector) string {
+	v, err := readMachineID(c.fsRoot())
+	if err != nil {
+		c.addErr("machineguid", err, "")
+	}
+	return v
+}
@@ -0,0 +1,17 @@
+package main
+
+import "testing"
+
+func TestReadMachineID(t *testing.T) {
+	for root, want := range map[string]string{
+		desktopRoot: "0f3a6c1e9b2d4f7a8c5e1d2b3a4f6e7d",
+		armRoot:     "b8e4f2a17c3d4e5f9a0b1c2d3e4f5a6b", // empty /etc copy, dbus one used
+	} {
+		if got, err := readMachineID(root); got != want || err != nil {
+			t.Errorf("readMachineID(%s) = %q, %v", root, got, err)
+		}
+	}
+	if _, err := readMachineID(t.TempDir()); err == nil {
+		t.Error("no machine-id: no error")
+	}
+}
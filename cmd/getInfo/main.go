@@ -1,85 +1,181 @@
 package main
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
-	"path/filepath"
-	"strconv"
+	"os"
 	"strings"
-	"time"
 )
 
 func main() {
-	// Require 3 positional args: <Patrimonio> <Nome> <Local...>
-	args := Args()
-	if len(args) < 3 {
+	args := initLanguage(Args())
+	stdin := bufio.NewReader(os.Stdin)
+	if len(args) > 0 {
+		if cmd := findCommand(args[0]); cmd != nil {
+			os.Exit(cmd.run(args[1:], stdin))
+		}
+	}
+
+	// Legacy form kept for existing scripts: <Patrimonio> <Nome> <Local...>.
+	// No args = most likely a double-click: keep the window open at the end.
+	interactive := len(args) == 0
+	req := collectRequest{}
+	if len(args) >= 3 {
+		req.in = operatorInput{patr: args[0], nome: args[1], local: strings.TrimSpace(strings.Join(args[2:], " "))}
+		req.haveInput = true
+	} else if !interactive {
 		PrintUsageAndExit()
 	}
-	inPatr := args[0]
-	inNome := args[1]
-	inLocal := strings.TrimSpace(strings.Join(args[2:], " "))
+	code := runCollect(req, stdin)
+	if interactive {
+		waitEnter(stdin, os.Stdout)
+	}
+	os.Exit(code)
+}
 
+type collectRequest struct {
+	in        operatorInput
+	haveInput bool // false = prompt (when ui.interactiveIfNoArgs allows it)
+	xlsx      bool // force the XLSX / JSON / NDJSON sinks on for this run
+	json      bool
+	ndjson    bool
+}
+
+// runCollect is the default behavior: collect, write every sink, log errors.
+func runCollect(req collectRequest, stdin *bufio.Reader) int {
 	base := exeDir()
-	csvPath := filepath.Join(base, CsvName)
-	errLog := filepath.Join(base, ErrLogName)
+	cfg, err := loadConfig(base)
+	if err != nil {
+		fmt.Println(tr("err.config", err))
+		return 1
+	}
+	cfg.Output.Excel = cfg.Output.Excel || req.xlsx
+	cfg.Output.JSON = cfg.Output.JSON || req.json
+	cfg.Output.NDJSON = cfg.Output.NDJSON || req.ndjson
+	if !req.haveInput && !cfg.UI.InteractiveIfNoArgs {
+		PrintUsageAndExit()
+	}
 
-	c := &collector{}
+	errLog := cfg.errLogPath(base)
+	if err := os.MkdirAll(cfg.outputDir(base), 0755); err != nil {
+		fmt.Println(tr("err.outdir", err))
+		return 1
+	}
 
-	// --- Coleta (cada função falhando retorna "" e loga o erro) ---
-	sn := getSerial(c)
-	uuid := getUUIDSMBIOS(c)
-	mguid := getMachineGuid(c)
-	host := getHostname(c)
-	user := getCurrentUser(c)
-	mstsc := getRDPUser(c)
-	ip := getActiveIPv4(c) // correct active IPv4 (default route)
-	win := getWindowsVersion(c)
-	cpu := getCPU(c)
-	ramGB := getTotalRAMGiB(c)
-	used, total, okUsed, okTotal := getRAMSlots(c)
+	c, finishRunner, err := newRunCollector(cfg)
+	if err != nil {
+		fmt.Println(tr("err.fixtures", err))
+		return 1
+	}
 
-	var slotsUs, slotsTot, slotsLiv string
-	if okUsed {
-		slotsUs = strconv.FormatInt(used, 10)
+	// --- Coleta (cada coletor falhando deixa "" e loga o erro) ---
+	vals, err := gather(c, cfg, cfg.collectors(false), func() (operatorInput, error) {
+		if req.haveInput {
+			return req.in, nil
+		}
+		fmt.Println(tr("main.collecting"))
+		return promptInput(stdin, os.Stdout)
+	})
+	if err != nil {
+		fmt.Println("\n" + tr("err.operator_input", err))
+		return 1
 	}
-	if okTotal {
-		slotsTot = strconv.FormatInt(total, 10)
+	if err := finishRunner(); err != nil {
+		c.addErr("fixtures", err, Getenv(envRecord))
 	}
-	if okUsed && okTotal && total >= used {
-		slotsLiv = strconv.FormatInt(total-used, 10)
+	rec := newRecord(vals, c.runID)
+	if cfg.Output.CSV || cfg.Output.Excel {
+		reportSanitized(c, cfg, rec)
 	}
 
-	diskGB, freeGB := getDiskSystemGiB(c)
-	ssd := getIsSSD(c)
-	adID := anydeskGetID(c)
-
-	// Set AnyDesk password (requires admin; manifest should ensure elevation)
-	anydeskSetPassword(c, AnyDeskPassword)
+	// --- Saidas (CSV, XLSX, JSON, NDJSON, HTTP) ---
+	failed := 0
+	var results []sinkResult
+	for _, sk := range cfg.sinks(base, c) {
+		err := sk.Write(rec)
+		results = append(results, sinkResult{sk.Name(), err})
+		var warn sinkWarning
+		switch {
+		case err == nil:
+		case errors.As(err, &warn):
+			c.addErr("output_"+sk.Name(), err, "")
+			fmt.Println(tr("sink.warning", sk.Name(), err))
+		default:
+			failed++
+			c.addErr("output_"+sk.Name(), err, "")
+			fmt.Println(tr("sink.failed", sk.Name(), err))
+		}
+	}
+	writeErrors(errLog, c.errors())
 
-	now := time.Now().Format("2006-01-02 15:04:05")
+	if cfg.Output.ShowSummaryInConsole {
+		printSummary(os.Stdout, cfg.Vocabulary.render(rec.Values()))
+		printSinkResults(os.Stdout, results)
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
 
-	// --- CSV ---
+// reportSanitized logs (and shows) every value the spreadsheet sinks will
+// write differently from what was collected. Done once here, before the
+// sinks, so it also lands in the workbook's Erros sheet.
+func reportSanitized(c *collector, cfg *Config, rec *InventoryRecord) {
+	_, report := cfg.sanitizer().values(rec.Values())
+	for _, a := range report {
+		msg := tr("sanitize.altered", a.field.Header, tr(a.reason), cfg.Output.Sanitize, a.before, a.after)
+		c.addErr("sanitize", errors.New(msg), "")
+		fmt.Println(tr("sink.sanitized", msg))
+	}
+}
 
-	f, err := ensureCSVReady(csvPath, Headers)
+// newRunCollector wires the runner (record/replay) and per-run settings.
+func newRunCollector(cfg *Config) (*collector, func() error, error) {
+	runner, plat, finish, err := runnerFromEnv()
 	if err != nil {
-		c.addErr("csv_prepare", err, csvPath)
-		writeErrors(errLog, c.errs)
-		fmt.Println("erro ao preparar CSV:", err)
-		return
+		return nil, nil, err
 	}
-	defer f.Close()
+	c := newCollector()
+	c.runner = runner
+	c.plat = plat
+	c.root = Getenv(envRoot)
+	c.cfg = cfg
+	c.smbiosSrc = newSMBIOSSource(c.fsRoot())
+	return c, finish, nil
+}
+
+// gather runs cols (see Config.collectors). Hardware runs in the background while
+// ask obtains the operator fields; the input collector runs last, on its own
+// copy, once the answers are known.
+func gather(c *collector, cfg *Config, cols []Collector, ask func() (operatorInput, error)) (Values, error) {
+	inputCols, hwCols := splitInputCollectors(cols)
+	opts := cfg.collectOptions()
+	hwDone := make(chan Values, 1)
+	go func() { hwDone <- collectAll(c, hwCols, opts) }()
 
-	row := []string{
-		sn, uuid, mguid, inPatr, inNome, inLocal,
-		host, user, mstsc, ip, win, cpu, ramGB,
-		slotsUs, slotsTot, slotsLiv, diskGB, freeGB, ssd,
-		adID, now,
-	}
-	if err := appendCSVRow(f, row); err != nil {
-		c.addErr("csv_append", err, csvPath)
-		writeErrors(errLog, c.errs)
-		fmt.Println("erro ao gravar linha no CSV:", err)
-		return
+	in, err := ask()
+	if err != nil {
+		return nil, err
 	}
+	ci := c.withContext(c.ctx)
+	ci.in = in
+	vals := collectAll(ci, inputCols, opts)
+	for k, v := range <-hwDone {
+		vals[k] = v
+	}
+	return vals, nil
+}
 
-	writeErrors(errLog, c.errs)
+// The "input" collector reads operator answers, so it cannot start with the rest.
+func splitInputCollectors(cols []Collector) (input, rest []Collector) {
+	for _, col := range cols {
+		if col.Name() == "input" {
+			input = append(input, col)
+		} else {
+			rest = append(rest, col)
+		}
+	}
+	return input, rest
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MemoryModule is one memory slot as the firmware describes it. The list
+// goes to the JSON record only; the CSV gets the RAM_Type summary.
+type MemoryModule struct {
+	Slot          string `json:"slot"`
+	Bank          string `json:"bank,omitempty"`
+	SizeMB        int64  `json:"sizeMB"` // 0 = empty slot
+	Type          string `json:"type,omitempty"`
+	FormFactor    string `json:"formFactor,omitempty"`
+	SpeedMTs      int    `json:"speedMTs,omitempty"`      // rated
+	ConfiguredMTs int    `json:"configuredMTs,omitempty"` // running
+	Manufacturer  string `json:"manufacturer,omitempty"`
+	PartNumber    string `json:"partNumber,omitempty"`
+	Serial        string `json:"serial,omitempty"`
+}
+
+// memoryModulesKey carries the JSON-encoded module list from the collector to
+// newRecord. It is not a column.
+const memoryModulesKey = "ram_modules"
+
+func collectRAMModules(c *collector) Values {
+	mods := getRAMModules(c)
+	if len(mods) == 0 {
+		c.addErr("ram_type", ErrNotFound, "")
+		return Values{}
+	}
+	v := Values{FieldRAMType.Key: memorySummary(mods)}
+	if b, err := json.Marshal(mods); err == nil {
+		v[memoryModulesKey] = string(b)
+	}
+	return v
+}
+
+// memoryModules lists the system-memory slots from SMBIOS type 17.
+func (info *smbiosInfo) memoryModules() []MemoryModule {
+	system := map[uint16]bool{}
+	for _, a := range info.MemoryArrays {
+		if a.Use == 0x03 {
+			system[a.handle] = true
+		}
+	}
+	var out []MemoryModule
+	for _, d := range info.MemoryDevices {
+		if len(system) > 0 && !system[d.arrayHandle] {
+			continue
+		}
+		m := MemoryModule{Slot: d.Locator, Bank: d.BankLocator, SizeMB: d.SizeMB}
+		if d.SizeMB > 0 {
+			m.Type = memoryTypeName(d.Type)
+			m.FormFactor = memoryFormFactors[d.FormFactor]
+			m.SpeedMTs, m.ConfiguredMTs = d.SpeedMTs, d.ConfiguredMTs
+			m.Manufacturer, m.PartNumber, m.Serial = d.Manufacturer, d.PartNumber, d.Serial
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// SMBIOS memory types (type 17 offset 0x12, Win32_PhysicalMemory
+// SMBIOSMemoryType). Anything not listed is reported as unknown.
+var memoryTypes = map[uint8]string{
+	0x03: "DRAM", 0x0F: "SDRAM", 0x11: "RDRAM",
+	0x12: "DDR", 0x13: "DDR2", 0x14: "DDR2 FB-DIMM", 0x18: "DDR3", 0x19: "FBD2",
+	0x1A: "DDR4", 0x1B: "LPDDR", 0x1C: "LPDDR2", 0x1D: "LPDDR3", 0x1E: "LPDDR4",
+	0x20: "HBM", 0x21: "HBM2", 0x22: "DDR5", 0x23: "LPDDR5", 0x24: "HBM3",
+}
+
+func memoryTypeName(t uint8) string { return memoryTypes[t] }
+
+var memoryFormFactors = map[uint8]string{
+	0x09: "DIMM", 0x0C: "RIMM", 0x0D: "SODIMM", 0x0F: "FB-DIMM", 0x0B: "Row of chips",
+}
+
+// memorySummary renders the installed modules as "DDR4 2x8GB 3200": type,
+// module counts by size, and the speed they actually run at (the slowest
+// configured speed, else the slowest rated one).
+func memorySummary(mods []MemoryModule) string {
+	var types []string
+	count := map[int64]int{}
+	configured, rated := 0, 0
+	for _, m := range mods {
+		if m.SizeMB <= 0 {
+			continue
+		}
+		if m.Type != "" && !slices.Contains(types, m.Type) {
+			types = append(types, m.Type)
+		}
+		count[m.SizeMB]++
+		configured = slowest(configured, m.ConfiguredMTs)
+		rated = slowest(rated, m.SpeedMTs)
+	}
+	if len(count) == 0 {
+		return ""
+	}
+	sizes := make([]int64, 0, len(count))
+	for s := range count {
+		sizes = append(sizes, s)
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+	groups := make([]string, len(sizes))
+	for i, s := range sizes {
+		groups[i] = strconv.Itoa(count[s]) + "x" + fmtMemorySize(s)
+	}
+
+	parts := []string{}
+	if len(types) > 0 {
+		parts = append(parts, strings.Join(types, "/"))
+	}
+	parts = append(parts, strings.Join(groups, "+"))
+	if configured == 0 {
+		configured = rated
+	}
+	if configured > 0 {
+		parts = append(parts, strconv.Itoa(configured))
+	}
+	return strings.Join(parts, " ")
+}
+
+func slowest(cur, v int) int {
+	if v > 0 && (cur == 0 || v < cur) {
+		return v
+	}
+	return cur
+}
+
+func fmtMemorySize(mb int64) string {
+	if mb%1024 == 0 {
+		return strconv.FormatInt(mb/1024, 10) + "GB"
+	}
+	return strconv.FormatInt(mb, 10) + "MB"
+}
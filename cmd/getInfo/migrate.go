@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// csvMigration describes a header rewrite done by migrateCSVHeader.
+type csvMigration struct {
+	added   []string // columns new in this build
+	kept    []string // old-only columns, kept at the end
+	backup  string
+	renamed bool // same columns, different order
+}
+
+func (m csvMigration) String() string {
+	var parts []string
+	if len(m.added) > 0 {
+		parts = append(parts, tr("mig.added", strings.Join(m.added, ", ")))
+	}
+	if len(m.kept) > 0 {
+		parts = append(parts, tr("mig.kept", strings.Join(m.kept, ", ")))
+	}
+	if m.renamed {
+		parts = append(parts, tr("mig.reordered"))
+	}
+	parts = append(parts, tr("mig.backup", m.backup))
+	return strings.Join(parts, "; ")
+}
+
+// migrateCSVHeader makes an existing CSV match the current schema: when its
+// header differs, a timestamped backup is taken and the file is rewritten
+// with the merged header (current columns, then old-only ones), old rows
+// re-mapped by column name. It returns the header now in the file and the
+// migration done, if any.
+func migrateCSVHeader(path string, header []string, d csvDialect) ([]string, *csvMigration, error) {
+	oldHeader, rows, err := readInventoryCSV(path, d)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged := mergeHeader(oldHeader, header)
+	if sameHeader(merged, oldHeader) {
+		return oldHeader, nil, nil
+	}
+
+	m := &csvMigration{}
+	old := columnIndex(oldHeader)
+	for _, h := range header {
+		if _, ok := old[h]; !ok {
+			m.added = append(m.added, h)
+		}
+	}
+	m.kept = merged[len(header):]
+	m.renamed = len(m.added) == 0 && len(m.kept) == 0
+
+	if m.backup, err = copyToBackup(path); err != nil {
+		return nil, nil, trErr("csv.backup", err)
+	}
+	for i := range rows {
+		rows[i] = remapRow(rows[i], oldHeader, merged)
+	}
+	if err := writeCSVFile(path, merged, rows, d); err != nil {
+		return nil, nil, err
+	}
+	return merged, m, nil
+}
+
+// writeBackup saves data as path.<timestamp>.bak, adding -2, -3... when a
+// backup was already taken in the same second, and returns the name used.
+func writeBackup(path string, data []byte) (string, error) {
+	stamp := path + "." + time.Now().Format("20060102-150405")
+	for n := 1; ; n++ {
+		name := stamp + ".bak"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.bak", stamp, n)
+		}
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if errors.Is(err, fs.ErrExist) && n < 1000 {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return name, err
+	}
+}
+
+// copyToBackup copies src to a new backup next to it.
+func copyToBackup(src string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", err
+	}
+	return writeBackup(src, data)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// A CSV from an older build keeps its rows under the right columns: the
+// header is rebuilt by name, old-only columns move to the end and the
+// original is backed up first.
+func TestMigrateCSVHeader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, CsvName)
+	old := "\xEF\xBB\xBFsep=,\r\nHost,Obs,SN\r\nPC-01,troca de tela,SN1\r\n"
+	os.WriteFile(path, []byte(old), 0644)
+
+	header, mig, err := migrateCSVHeader(path, Headers(), defaultDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mig == nil || !slices.Equal(mig.kept, []string{"Obs"}) || len(mig.added) != len(Headers())-2 {
+		t.Fatalf("migration = %+v", mig)
+	}
+	if !slices.Equal(header, append(Headers(), "Obs")) {
+		t.Errorf("header = %q", header)
+	}
+	_, rows, _ := readInventoryCSV(path, defaultDialect)
+	col := columnIndex(header)
+	if len(rows) != 1 || rows[0][col["SN"]] != "SN1" || rows[0][col["Host"]] != "PC-01" || rows[0][col["Obs"]] != "troca de tela" {
+		t.Errorf("rows = %q", rows)
+	}
+	if data, err := os.ReadFile(mig.backup); err != nil || string(data) != old {
+		t.Errorf("backup %s: %v, %q", mig.backup, err, data)
+	}
+
+	// Already current: nothing to do.
+	if _, mig, err := migrateCSVHeader(path, Headers(), defaultDialect); mig != nil || err != nil {
+		t.Errorf("second pass: %+v, %v", mig, err)
+	}
+}
+
+// The collect run appends its row under the migrated header and logs it.
+func TestCSVSinkMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CsvName)
+	os.WriteFile(path, []byte("\xEF\xBB\xBFsep=,\r\nSN,Host\r\nSN1,PC-01\r\n"), 0644)
+	var logged []string
+	s := csvSink{path: path, dialect: defaultDialect, log: func(ctx string, err error, detail string) { logged = append(logged, ctx) }}
+	if err := s.Write(newRecord(Values{FieldSN.Key: "SN2", FieldHost.Key: "PC-02"}, "run-1")); err != nil {
+		t.Fatal(err)
+	}
+	header, rows, _ := readInventoryCSV(path, defaultDialect)
+	col := columnIndex(header)
+	if !slices.Equal(header, Headers()) || len(rows) != 2 || rows[1][col["SN"]] != "SN2" || rows[1][col["Host"]] != "PC-02" {
+		t.Errorf("header %q, rows %q", header, rows)
+	}
+	if !slices.Equal(logged, []string{"csv_migration"}) {
+		t.Errorf("logged %q", logged)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import "runtime"
+
+// platform is the per-OS half of the collectors. The Windows one talks to the
+// machine only through the Runner (PowerShell, WMIC, reg.exe), so its files
+// (win_*.go) carry no build tag and a run recorded on a workstation replays
+// on Linux CI with the same parsing.
+type platform struct {
+	goos string
+
+	serial, uuid, machineGuid func(c *collector) string
+	user, rdpUser, osVersion  func(c *collector) string
+	gatewayIPv4               func(c *collector) string
+	cpu, totalRAMGiB          func(c *collector) string
+	ramModules                func(c *collector) []MemoryModule
+	ramSlots                  func(c *collector) (used, total int64, okUsed, okTotal bool)
+	diskSystemGiB             func(c *collector) (total, free string)
+	storage                   func(c *collector) (*storageInfo, error)
+	gpus                      func(c *collector) ([]GPUAdapter, error)
+	security                  func(c *collector) (*securityInfo, error)
+	isAdmin                   func(c *collector) bool
+	shell                     func(line string) (string, []string)
+}
+
+// nativePlatform is this build's, set by platform_<goos>.go (an init, since
+// the collectors refer back to it through c.platform).
+var nativePlatform *platform
+
+var windowsPlatform = &platform{
+	goos:          "windows",
+	serial:        windowsSerial,
+	uuid:          windowsUUID,
+	machineGuid:   windowsMachineGuid,
+	user:          windowsCurrentUser,
+	rdpUser:       windowsRDPUser,
+	osVersion:     windowsOSVersion,
+	gatewayIPv4:   windowsGatewayIPv4,
+	cpu:           windowsCPU,
+	totalRAMGiB:   windowsTotalRAMGiB,
+	ramModules:    windowsRAMModules,
+	ramSlots:      windowsRAMSlots,
+	diskSystemGiB: windowsDiskSystemGiB,
+	storage:       windowsReadStorage,
+	gpus:          windowsGPUs,
+	security:      windowsSecurity,
+	isAdmin:       windowsIsAdmin,
+	shell:         windowsShell,
+}
+
+// platformFor picks the collectors for a recording made on goos ("" = this
+// machine). Only Windows runs can be replayed on another OS.
+func platformFor(goos string) (*platform, error) {
+	switch goos {
+	case "", runtime.GOOS:
+		return nativePlatform, nil
+	case windowsPlatform.goos:
+		return windowsPlatform, nil
+	}
+	return nil, trErr("runner.platform", goos, runtime.GOOS)
+}
+
+func (c *collector) platform() *platform {
+	if c.plat == nil {
+		return nativePlatform
+	}
+	return c.plat
+}
+
+// Hooks called by the collectors, answered by the run's platform.
+func getSerial(c *collector) string                   { return c.platform().serial(c) }
+func getUUIDSMBIOS(c *collector) string               { return c.platform().uuid(c) }
+func getMachineGuid(c *collector) string              { return c.platform().machineGuid(c) }
+func getCurrentUser(c *collector) string              { return c.platform().user(c) }
+func getRDPUser(c *collector) string                  { return c.platform().rdpUser(c) }
+func getOSVersion(c *collector) string                { return c.platform().osVersion(c) }
+func defaultGatewayIPv4(c *collector) string          { return c.platform().gatewayIPv4(c) }
+func getCPU(c *collector) string                      { return c.platform().cpu(c) }
+func getTotalRAMGiB(c *collector) string              { return c.platform().totalRAMGiB(c) }
+func getRAMModules(c *collector) []MemoryModule       { return c.platform().ramModules(c) }
+func readStorage(c *collector) (*storageInfo, error)  { return c.platform().storage(c) }
+func getGPUs(c *collector) ([]GPUAdapter, error)      { return c.platform().gpus(c) }
+func getSecurity(c *collector) (*securityInfo, error) { return c.platform().security(c) }
+func isAdmin(c *collector) bool                       { return c.platform().isAdmin(c) }
+
+func getRAMSlots(c *collector) (used, total int64, okUsed, okTotal bool) {
+	return c.platform().ramSlots(c)
+}
+
+func getDiskSystemGiB(c *collector) (total, free string) {
+	return c.platform().diskSystemGiB(c)
+}
@@ -0,0 +1,25 @@
+package main
+
+// linuxPlatform reads /sys, /proc and /etc under c.root.
+var linuxPlatform = &platform{
+	goos:          "linux",
+	serial:        linuxSerial,
+	uuid:          linuxUUID,
+	machineGuid:   linuxMachineGuid,
+	user:          linuxCurrentUser,
+	rdpUser:       linuxRDPUser,
+	osVersion:     linuxOSVersion,
+	gatewayIPv4:   linuxGatewayIPv4,
+	cpu:           linuxCPU,
+	totalRAMGiB:   linuxTotalRAMGiB,
+	ramModules:    linuxRAMModules,
+	ramSlots:      linuxRAMSlots,
+	diskSystemGiB: linuxDiskSystemGiB,
+	storage:       linuxReadStorage,
+	gpus:          linuxGPUs,
+	security:      linuxSecurity,
+	isAdmin:       linuxIsAdmin,
+	shell:         linuxShell,
+}
+
+func init() { nativePlatform = linuxPlatform }
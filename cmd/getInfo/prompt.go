@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxInputLen = 64
+
+// Interactive mode (double-click): ask for each operator field until it is
+// valid. Collection is already running in the background meanwhile.
+func promptInput(r *bufio.Reader, w io.Writer) (operatorInput, error) {
+	var in operatorInput
+	var err error
+	if in.patr, err = askField(r, w, tr("prompt.asset")); err != nil {
+		return in, err
+	}
+	if in.nome, err = askField(r, w, tr("prompt.name")); err != nil {
+		return in, err
+	}
+	if in.local, err = askField(r, w, tr("prompt.location")); err != nil {
+		return in, err
+	}
+	return in, nil
+}
+
+func askField(r *bufio.Reader, w io.Writer, label string) (string, error) {
+	for {
+		fmt.Fprintf(w, "%s: ", label)
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				return "", errors.New(tr("prompt.closed"))
+			}
+			return "", err
+		}
+		v := strings.TrimSpace(line)
+		if msg := validateInput(v); msg != "" {
+			fmt.Fprintln(w, tr("prompt.invalid", label, msg))
+			if err == io.EOF {
+				return "", errors.New(tr("prompt.closed"))
+			}
+			continue
+		}
+		return v, nil
+	}
+}
+
+// Same rules for every field: required, short, printable.
+func validateInput(v string) string {
+	switch {
+	case v == "":
+		return tr("prompt.required")
+	case utf8.RuneCountInString(v) > maxInputLen:
+		return tr("prompt.too_long", maxInputLen)
+	case strings.IndexFunc(v, unicode.IsControl) >= 0:
+		return tr("prompt.control")
+	}
+	return ""
+}
+
+// One line per column, labels aligned.
+func printSummary(w io.Writer, vals Values) {
+	fs := Fields()
+	width := 0
+	for _, f := range fs {
+		width = max(width, len(f.Label()))
+	}
+	fmt.Fprintln(w)
+	for _, f := range fs {
+		fmt.Fprintf(w, "%-*s  %s\n", width+1, f.Label()+":", vals[f.Key])
+	}
+}
+
+// Outcome of each output, after the summary.
+func printSinkResults(w io.Writer, results []sinkResult) {
+	fmt.Fprintln(w)
+	for _, r := range results {
+		var warn sinkWarning
+		status := tr("summary.ok")
+		switch {
+		case r.err == nil:
+		case errors.As(r.err, &warn):
+			status = tr("summary.warning")
+		default:
+			status = tr("summary.failed")
+		}
+		fmt.Fprintf(w, "%-8s %s\n", r.name+":", status)
+	}
+}
+
+// Keeps the console open when started by double-click.
+func waitEnter(r *bufio.Reader, w io.Writer) {
+	fmt.Fprint(w, "\n"+tr("prompt.enter"))
+	_, _ = r.ReadString('\n')
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+// Invalid answers are refused and asked again; a final line without a
+// newline still counts.
+func TestPromptInput(t *testing.T) {
+	long := strings.Repeat("x", maxInputLen+1)
+	r := bufio.NewReader(strings.NewReader("\n  PAT-0042 \n" + long + "\nAna\tB\nAna Souza\nSala 3"))
+	var w strings.Builder
+	in, err := promptInput(r, &w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in != (operatorInput{patr: "PAT-0042", nome: "Ana Souza", local: "Sala 3"}) {
+		t.Errorf("input = %+v", in)
+	}
+	if n := strings.Count(w.String(), "invalido"); n != 3 {
+		t.Errorf("%d refusals:\n%s", n, w.String())
+	}
+}
+
+func TestPromptInputClosed(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("PAT-0042\n\n"))
+	if _, err := promptInput(r, &strings.Builder{}); err == nil {
+		t.Error("no error at end of input")
+	}
+}
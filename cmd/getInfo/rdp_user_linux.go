@@ -0,0 +1,4 @@
+package main
+
+// No RDP sessions to report on Linux.
+func linuxRDPUser(c *collector) string { return "" }
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// SchemaVersion changes whenever InventoryRecord's JSON shape changes in a
+// way downstream scripts must know about.
+const SchemaVersion = 1
+
+const dateLayout = "2006-01-02 15:04:05"
+
+// InventoryRecord is one run's result. CSV (and anything else) is just a
+// rendering of it; nil pointers mean "unknown".
+type InventoryRecord struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	RunID         string `json:"runId"`
+
+	SN          string `json:"sn"`
+	UUID        string `json:"uuid"`
+	MachineGuid string `json:"machineGuid"`
+
+	Asset    string `json:"asset"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+
+	Host      string `json:"host"`
+	User      string `json:"user"`
+	RDPUser   string `json:"rdpUser"`
+	IP        string `json:"ip"`
+	OSVersion string `json:"osVersion"`
+	CPU       string `json:"cpu"`
+
+	RAMGB      *int64 `json:"ramGB,omitempty"`
+	RAMType    string `json:"ramType"` // summary, e.g. "DDR4 2x8GB 3200"
+	SlotsUsed  *int64 `json:"slotsUsed,omitempty"`
+	SlotsTotal *int64 `json:"slotsTotal,omitempty"`
+	SlotsFree  *int64 `json:"slotsFree,omitempty"`
+
+	// One entry per slot, empty ones included (sizeMB 0). Not a CSV column.
+	Memory []MemoryModule `json:"memory,omitempty"`
+
+	DiskGB *int64 `json:"diskGB,omitempty"`
+	FreeGB *int64 `json:"freeGB,omitempty"`
+	SSD    *bool  `json:"ssd,omitempty"` // the disk holding the system volume
+	Disks  string `json:"disks"`         // summary, system disk first
+
+	// Every physical disk and volume. Not CSV columns.
+	PhysicalDisks []PhysicalDisk `json:"physicalDisks,omitempty"`
+	Volumes       []Volume       `json:"volumes,omitempty"`
+
+	GPU       *bool    `json:"gpu,omitempty"`
+	GPUModel  string   `json:"gpuModel"`
+	GPUVRAMGB *float64 `json:"gpuVramGB,omitempty"` // one decimal
+	GPUDriver string   `json:"gpuDriver"`
+
+	// Every video controller, most dedicated memory first. Not a CSV column.
+	GPUs []GPUAdapter `json:"gpus,omitempty"`
+
+	AnyDeskID string `json:"anydeskId"`
+
+	Antivirus string `json:"antivirus"` // Security Center summary, in the run's language
+	Defender  string `json:"defender"`
+	BDProduct string `json:"bdProduct"`
+
+	AntivirusProducts []AVProduct     `json:"antivirusProducts,omitempty"`
+	DefenderStatus    *DefenderStatus `json:"defenderStatus,omitempty"`
+
+	Date time.Time `json:"date"`
+
+	// First and latest run seen for this machine (see output.mode "upsert").
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+
+	// Columns from site-specific collectors, by Field.Key.
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// newRunID returns a random UUID (v4) identifying one execution.
+func newRunID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+// builtinKeys are the columns InventoryRecord has typed fields for; anything
+// else goes to Extra.
+var builtinKeys = map[string]bool{}
+
+func init() {
+	for _, f := range []Field{
+		FieldSN, FieldUUID, FieldMGuid, FieldPatr, FieldNome, FieldLocal,
+		FieldHost, FieldUser, FieldMSTSC, FieldIP, FieldWin, FieldCPU,
+		FieldRAM, FieldRAMType, FieldSlotUs, FieldSlotTot, FieldSlotLiv,
+		FieldDisk, FieldLivre, FieldSSD, FieldDisks,
+		FieldGPU, FieldGPUModel, FieldGPUVRAM, FieldGPUDriver,
+		FieldADID, FieldAV, FieldDefender, FieldBD, FieldData,
+		FieldFirstSeen, FieldLastSeen,
+	} {
+		builtinKeys[f.Key] = true
+	}
+	builtinKeys[memoryModulesKey] = true
+	builtinKeys[gpusKey] = true
+	builtinKeys[avProductsKey] = true
+	builtinKeys[defenderKey] = true
+	builtinKeys[physicalDisksKey] = true
+	builtinKeys[volumesKey] = true
+}
+
+// newRecord types the collected strings. Values that do not parse are kept
+// as unknown rather than guessed.
+func newRecord(vals Values, runID string) *InventoryRecord {
+	r := &InventoryRecord{
+		SchemaVersion: SchemaVersion,
+		RunID:         runID,
+		SN:            vals[FieldSN.Key],
+		UUID:          vals[FieldUUID.Key],
+		MachineGuid:   vals[FieldMGuid.Key],
+		Asset:         vals[FieldPatr.Key],
+		Name:          vals[FieldNome.Key],
+		Location:      vals[FieldLocal.Key],
+		Host:          vals[FieldHost.Key],
+		User:          vals[FieldUser.Key],
+		RDPUser:       vals[FieldMSTSC.Key],
+		IP:            vals[FieldIP.Key],
+		OSVersion:     vals[FieldWin.Key],
+		CPU:           vals[FieldCPU.Key],
+		RAMGB:         optInt(vals[FieldRAM.Key]),
+		RAMType:       vals[FieldRAMType.Key],
+		SlotsUsed:     optInt(vals[FieldSlotUs.Key]),
+		SlotsTotal:    optInt(vals[FieldSlotTot.Key]),
+		SlotsFree:     optInt(vals[FieldSlotLiv.Key]),
+		DiskGB:        optInt(vals[FieldDisk.Key]),
+		FreeGB:        optInt(vals[FieldLivre.Key]),
+		SSD:           optBool(vals[FieldSSD.Key]),
+		Disks:         vals[FieldDisks.Key],
+		GPU:           optBool(vals[FieldGPU.Key]),
+		GPUModel:      vals[FieldGPUModel.Key],
+		GPUVRAMGB:     optFloat(vals[FieldGPUVRAM.Key]),
+		GPUDriver:     vals[FieldGPUDriver.Key],
+		AnyDeskID:     vals[FieldADID.Key],
+		Antivirus:     vals[FieldAV.Key],
+		Defender:      vals[FieldDefender.Key],
+		BDProduct:     vals[FieldBD.Key],
+	}
+	if s := vals[memoryModulesKey]; s != "" {
+		_ = json.Unmarshal([]byte(s), &r.Memory)
+	}
+	if s := vals[gpusKey]; s != "" {
+		_ = json.Unmarshal([]byte(s), &r.GPUs)
+	}
+	if s := vals[physicalDisksKey]; s != "" {
+		_ = json.Unmarshal([]byte(s), &r.PhysicalDisks)
+	}
+	if s := vals[volumesKey]; s != "" {
+		_ = json.Unmarshal([]byte(s), &r.Volumes)
+	}
+	if s := vals[avProductsKey]; s != "" {
+		_ = json.Unmarshal([]byte(s), &r.AntivirusProducts)
+	}
+	if s := vals[defenderKey]; s != "" {
+		_ = json.Unmarshal([]byte(s), &r.DefenderStatus)
+	}
+	if t, err := time.ParseInLocation(dateLayout, vals[FieldData.Key], time.Local); err == nil {
+		r.Date = t
+		r.FirstSeen, r.LastSeen = t, t
+	}
+	for k, v := range vals {
+		if !builtinKeys[k] {
+			if r.Extra == nil {
+				r.Extra = map[string]string{}
+			}
+			r.Extra[k] = v
+		}
+	}
+	return r
+}
+
+// Values renders the record back into column strings (CSV, console).
+func (r *InventoryRecord) Values() Values {
+	v := Values{
+		FieldSN.Key:        r.SN,
+		FieldUUID.Key:      r.UUID,
+		FieldMGuid.Key:     r.MachineGuid,
+		FieldPatr.Key:      r.Asset,
+		FieldNome.Key:      r.Name,
+		FieldLocal.Key:     r.Location,
+		FieldHost.Key:      r.Host,
+		FieldUser.Key:      r.User,
+		FieldMSTSC.Key:     r.RDPUser,
+		FieldIP.Key:        r.IP,
+		FieldWin.Key:       r.OSVersion,
+		FieldCPU.Key:       r.CPU,
+		FieldRAM.Key:       fmtInt(r.RAMGB),
+		FieldRAMType.Key:   r.RAMType,
+		FieldSlotUs.Key:    fmtInt(r.SlotsUsed),
+		FieldSlotTot.Key:   fmtInt(r.SlotsTotal),
+		FieldSlotLiv.Key:   fmtInt(r.SlotsFree),
+		FieldDisk.Key:      fmtInt(r.DiskGB),
+		FieldLivre.Key:     fmtInt(r.FreeGB),
+		FieldSSD.Key:       fmtBool(r.SSD),
+		FieldDisks.Key:     r.Disks,
+		FieldGPU.Key:       fmtBool(r.GPU),
+		FieldGPUModel.Key:  r.GPUModel,
+		FieldGPUVRAM.Key:   fmtFloat(r.GPUVRAMGB),
+		FieldGPUDriver.Key: r.GPUDriver,
+		FieldADID.Key:      r.AnyDeskID,
+		FieldAV.Key:        r.Antivirus,
+		FieldDefender.Key:  r.Defender,
+		FieldBD.Key:        r.BDProduct,
+	}
+	if !r.Date.IsZero() {
+		v[FieldData.Key] = r.Date.Format(dateLayout)
+	}
+	if !r.FirstSeen.IsZero() {
+		v[FieldFirstSeen.Key] = r.FirstSeen.Format(dateLayout)
+	}
+	if !r.LastSeen.IsZero() {
+		v[FieldLastSeen.Key] = r.LastSeen.Format(dateLayout)
+	}
+	for k, s := range r.Extra {
+		v[k] = s
+	}
+	return v
+}
+
+func optInt(s string) *int64 {
+	if s == "" {
+		return nil
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &i
+}
+
+func fmtInt(p *int64) string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatInt(*p, 10)
+}
+
+// optFloat takes plain decimals only ("0.5", "4"), like isNumber.
+func optFloat(s string) *float64 {
+	if !plainNumber.MatchString(s) {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil
+	}
+	return &f
+}
+
+func fmtFloat(p *float64) string {
+	if p == nil {
+		return ""
+	}
+	return fmtGiB(*p)
+}
+
+// fmtGiB is the one format of fractional GiB columns: one decimal.
+func fmtGiB(f float64) string { return strconv.FormatFloat(f, 'f', 1, 64) }
+
+// Collectors report valueYes/valueNo; the words shown come from the
+// vocabulary config.
+func optBool(s string) *bool {
+	var b bool
+	switch s {
+	case valueYes:
+		b = true
+	case valueNo:
+		b = false
+	default:
+		return nil
+	}
+	return &b
+}
+
+func fmtBool(p *bool) string {
+	switch {
+	case p == nil:
+		return ""
+	case *p:
+		return valueYes
+	default:
+		return valueNo
+	}
+}
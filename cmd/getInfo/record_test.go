@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Numbers and Sim/Nao become typed fields, anything that does not parse is
+// unknown, and site columns ride along in Extra.
+func TestNewRecord(t *testing.T) {
+	vals := Values{
+		FieldSN.Key:     "7XK3Q93",
+		FieldRAM.Key:    "16",
+		FieldSlotUs.Key: "2",
+		FieldDisk.Key:   "abc",
+		FieldSSD.Key:    valueYes,
+		FieldData.Key:   "2024-03-05 14:07:09",
+		"sala":          "3B",
+	}
+	r := newRecord(vals, "run-1")
+	if r.SchemaVersion != SchemaVersion || r.RunID != "run-1" || r.SN != "7XK3Q93" {
+		t.Errorf("record = %+v", r)
+	}
+	if r.RAMGB == nil || *r.RAMGB != 16 || r.DiskGB != nil || r.SSD == nil || !*r.SSD {
+		t.Errorf("typed fields: ram %v disk %v ssd %v", r.RAMGB, r.DiskGB, r.SSD)
+	}
+	if r.Extra["sala"] != "3B" || r.Date.Day() != 5 {
+		t.Errorf("extra %v, date %v", r.Extra, r.Date)
+	}
+
+	back := r.Values()
+	for k, want := range vals {
+		if k == FieldDisk.Key {
+			want = ""
+		}
+		if back[k] != want {
+			t.Errorf("Values()[%s] = %q, want %q", k, back[k], want)
+		}
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(data)
+	if !strings.Contains(s, `"schemaVersion":1`) || !strings.Contains(s, `"ramGB":16`) || strings.Contains(s, "diskGB") {
+		t.Errorf("json = %s", s)
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, b := newRunID(), newRunID()
+	if !re.MatchString(a) || a == b {
+		t.Errorf("run IDs %q, %q", a, b)
+	}
+}
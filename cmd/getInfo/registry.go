@@ -0,0 +1,164 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+)
+
+// Field describes one CSV column. Key is internal and stable; Header is the
+// short label operators see in Excel.
+type Field struct {
+	Key    string
+	Header string
+}
+
+// Values holds collected data by Field.Key ("" or missing = unknown).
+type Values map[string]string
+
+// Collector is one unit of inventory. To add a column, write a Collector and
+// Register it; the CSV header and row are derived from the registry order.
+type Collector interface {
+	Name() string
+	Fields() []Field
+	Platforms() []string // GOOS values; empty means all
+	NeedsAdmin() bool
+	Collect(c *collector) Values
+}
+
+// SideEffecter is implemented by collectors that change the machine instead of
+// (or besides) reading it. show and doctor never run them.
+type SideEffecter interface {
+	SideEffects() bool
+}
+
+func hasSideEffects(col Collector) bool {
+	s, ok := col.(SideEffecter)
+	return ok && s.SideEffects()
+}
+
+// funcCollector adapts a plain function to Collector (used by the built-ins).
+type funcCollector struct {
+	name      string
+	fields    []Field
+	platforms []string
+	admin     bool
+	effects   bool // changes the machine (see SideEffecter)
+	fn        func(c *collector) Values
+}
+
+func (f funcCollector) Name() string                { return f.name }
+func (f funcCollector) Fields() []Field             { return f.fields }
+func (f funcCollector) Platforms() []string         { return f.platforms }
+func (f funcCollector) NeedsAdmin() bool            { return f.admin }
+func (f funcCollector) SideEffects() bool           { return f.effects }
+func (f funcCollector) Collect(c *collector) Values { return f.fn(c) }
+
+// single wraps the common "one function, one column" collector.
+func single(name string, fd Field, platforms []string, fn func(c *collector) string) Collector {
+	return funcCollector{
+		name:      name,
+		fields:    []Field{fd},
+		platforms: platforms,
+		fn:        func(c *collector) Values { return Values{fd.Key: fn(c)} },
+	}
+}
+
+var windowsOnly = []string{"windows"}
+
+// Built-ins are initialized before any init() runs, so site-specific
+// collectors registered from init() always land after them.
+var registry = builtinCollectors()
+
+// Register appends a collector; its fields become the last CSV columns.
+func Register(col Collector) { registry = append(registry, col) }
+
+func builtinCollectors() []Collector {
+	return []Collector{
+		single("serial", FieldSN, nil, getSerial),
+		single("uuid", FieldUUID, nil, getUUIDSMBIOS),
+		single("machineguid", FieldMGuid, nil, getMachineGuid),
+		funcCollector{
+			name:   "input",
+			fields: []Field{FieldPatr, FieldNome, FieldLocal},
+			fn: func(c *collector) Values {
+				return Values{FieldPatr.Key: c.in.patr, FieldNome.Key: c.in.nome, FieldLocal.Key: c.in.local}
+			},
+		},
+		single("hostname", FieldHost, nil, getHostname),
+		single("user", FieldUser, nil, getCurrentUser),
+		single("rdp", FieldMSTSC, windowsOnly, getRDPUser),
+		single("ip", FieldIP, nil, getActiveIPv4), // correct active IPv4 (default route)
+		single("os", FieldWin, nil, getOSVersion),
+		single("cpu", FieldCPU, nil, getCPU),
+		single("ram", FieldRAM, nil, getTotalRAMGiB),
+		funcCollector{
+			name:   "ram_modules",
+			fields: []Field{FieldRAMType},
+			fn:     collectRAMModules,
+		},
+		funcCollector{
+			name:   "ram_slots",
+			fields: []Field{FieldSlotUs, FieldSlotTot, FieldSlotLiv},
+			fn:     collectRAMSlots,
+		},
+		funcCollector{
+			name:   "disk",
+			fields: []Field{FieldDisk, FieldLivre},
+			fn: func(c *collector) Values {
+				total, free := getDiskSystemGiB(c)
+				return Values{FieldDisk.Key: total, FieldLivre.Key: free}
+			},
+		},
+		single("ssd", FieldSSD, nil, getIsSSD),
+		funcCollector{
+			name:   "storage",
+			fields: []Field{FieldDisks},
+			fn:     collectStorage,
+		},
+		funcCollector{
+			name:   "gpu",
+			fields: []Field{FieldGPU, FieldGPUModel, FieldGPUVRAM, FieldGPUDriver},
+			fn:     collectGPU,
+		},
+		single("anydesk_id", FieldADID, nil, anydeskGetID),
+		// Side effect only: no columns. Requires admin (manifest should ensure elevation).
+		funcCollector{
+			name:    "anydesk_password",
+			admin:   true,
+			effects: true,
+			fn: func(c *collector) Values {
+				anydeskSetPassword(c, c.cfg.AnyDesk.Password)
+				return nil
+			},
+		},
+		funcCollector{
+			name:      "antivirus",
+			fields:    []Field{FieldAV, FieldDefender, FieldBD},
+			platforms: windowsOnly,
+			fn:        collectSecurity,
+		},
+		single("date", FieldData, nil, func(c *collector) string { return c.now.Format(dateLayout) }),
+	}
+}
+
+func collectRAMSlots(c *collector) Values {
+	used, total, okUsed, okTotal := getRAMSlots(c)
+	v := Values{}
+	if okUsed {
+		v[FieldSlotUs.Key] = strconv.FormatInt(used, 10)
+	}
+	if okTotal {
+		v[FieldSlotTot.Key] = strconv.FormatInt(total, 10)
+	}
+	if okUsed && okTotal && total >= used {
+		v[FieldSlotLiv.Key] = strconv.FormatInt(total-used, 10)
+	}
+	return v
+}
+
+// supportsPlatform reports whether col runs on goos (the recorded machine's
+// when replaying).
+func supportsPlatform(col Collector, goos string) bool {
+	ps := col.Platforms()
+	return len(ps) == 0 || slices.Contains(ps, goos)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// logRunner keeps the name of every command started and fails them all.
+type logRunner struct{ names *[]string }
+
+func (r logRunner) Run(_ context.Context, cmd Command) (string, error) {
+	*r.names = append(*r.names, cmd.Name)
+	return "", ErrNotFound
+}
+
+// On Linux the native readers fill the hardware columns from the filesystem
+// root, the Windows-only ones stay empty and no Windows tool is started.
+func TestLinuxCollectors(t *testing.T) {
+	var names []string
+	c := newCollector()
+	c.runner, c.root = logRunner{&names}, desktopRoot
+	vals := collectAll(c, registry, testCollectOptions)
+	for _, name := range names {
+		switch name {
+		case "cmd", "powershell", "wmic", "reg":
+			t.Errorf("started %s", name)
+		}
+	}
+	for f, want := range map[Field]string{
+		FieldSN:    "7XK3Q93",
+		FieldUUID:  "4C4C4544-0058-4B10-8033-B7C04F513933",
+		FieldMGuid: "0f3a6c1e9b2d4f7a8c5e1d2b3a4f6e7d",
+		FieldWin:   "Ubuntu 22.04",
+		FieldCPU:   "Intel(R) Core(TM) i5-9500 CPU @ 3.00GHz",
+		FieldRAM:   "16",
+		FieldSSD:   valueYes,
+	} {
+		if got := vals[f.Key]; got != want {
+			t.Errorf("%s = %q, want %q", f.Header, got, want)
+		}
+	}
+	for _, f := range []Field{FieldMSTSC, FieldSlotUs, FieldSlotTot} {
+		if v, ok := vals[f.Key]; ok {
+			t.Errorf("%s = %q", f.Header, v)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// withRegistry swaps the registry for the length of one test.
+func withRegistry(t *testing.T, cols ...Collector) {
+	saved := registry
+	registry = cols
+	t.Cleanup(func() { registry = saved })
+}
+
+func TestRegistryHeaderAndRow(t *testing.T) {
+	withRegistry(t,
+		single("serial", FieldSN, nil, func(c *collector) string { return "7XK3Q93" }),
+		funcCollector{name: "input", fields: []Field{FieldPatr, FieldNome}},
+	)
+	if got := Headers(); !slices.Equal(got, []string{"SN", "Patr", "Nome", "First_Seen", "Last_Seen"}) {
+		t.Errorf("Headers() = %q", got)
+	}
+	if got := Row(Values{FieldNome.Key: "Ana", FieldSN.Key: "7XK3Q93"}); !slices.Equal(got, []string{"7XK3Q93", "", "Ana", "", ""}) {
+		t.Errorf("Row() = %q", got)
+	}
+}
+
+// A site collector registered later lands after the built-ins (only the
+// tracking columns follow), and a collector for another platform leaves its
+// column empty.
+func TestRegisterAppends(t *testing.T) {
+	withRegistry(t,
+		single("host", FieldHost, nil, func(c *collector) string { return "PC-01" }),
+		single("elsewhere", FieldSN, []string{"plan9"}, func(c *collector) string { return "X" }),
+	)
+	site := Field{"sala", "Sala"}
+	Register(single("sala", site, nil, func(c *collector) string { return "3B" }))
+
+	h := Headers()
+	if h[len(h)-3] != "Sala" {
+		t.Fatalf("Headers() = %q", h)
+	}
+	vals := collectAll(newCollector(), registry, testCollectOptions)
+	if got := Row(vals); !slices.Equal(got, []string{"PC-01", "", "3B", "", ""}) {
+		t.Errorf("Row() = %q", got)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command is one external process invocation.
+type Command struct {
+	Name    string
+	Args    []string
+	Stdin   string // never recorded: may carry the AnyDesk password
+	Timeout time.Duration
+}
+
+func (cmd Command) String() string {
+	return strings.TrimSpace(cmd.Name + " " + strings.Join(cmd.Args, " "))
+}
+
+// Runner executes external commands. Collectors go through c.runner instead
+// of os/exec so a field run can be recorded and replayed on another machine.
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (string, error)
+}
+
+// execRunner is the real thing.
+type execRunner struct{}
+
+func (execRunner) Run(ctx context.Context, cmd Command) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, cmd.Timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	ec := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	ec.Stdout, ec.Stderr = &out, &out
+	hideWindow(ec)
+	if cmd.Stdin != "" {
+		ec.Stdin = strings.NewReader(cmd.Stdin)
+	}
+
+	err := ec.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return strings.TrimSpace(out.String()), context.DeadlineExceeded
+	}
+	return strings.TrimSpace(out.String()), err
+}
+
+// fixture is one recorded command.
+type fixture struct {
+	Name     string   `json:"name"`
+	Args     []string `json:"args"`
+	Output   string   `json:"output"`
+	Err      string   `json:"err,omitempty"`
+	ExitCode int      `json:"exitCode"`
+}
+
+func (f fixture) key() string { return f.Name + "\x00" + strings.Join(f.Args, "\x00") }
+
+// recording is the fixture file. Facts are values collectors read without a
+// command (hostname, environment, the AnyDesk path); GOOS selects the
+// collectors on replay. Older files are a bare array of commands.
+type recording struct {
+	GOOS     string            `json:"goos"`
+	Facts    map[string]string `json:"facts,omitempty"`
+	Commands []fixture         `json:"commands"`
+}
+
+// factRunner is implemented by the record and replay runners, which keep the
+// facts with the commands.
+type factRunner interface {
+	fact(key string, probe func() string) string
+}
+
+// fact returns probe(), or what it returned on the recorded machine.
+func (c *collector) fact(key string, probe func() string) string {
+	if f, ok := c.runner.(factRunner); ok {
+		return f.fact(key, probe)
+	}
+	return probe()
+}
+
+// recordRunner wraps another runner and keeps every call for save.
+type recordRunner struct {
+	inner Runner
+	mu    sync.Mutex
+	calls []fixture
+	facts map[string]string
+}
+
+func (r *recordRunner) Run(ctx context.Context, cmd Command) (string, error) {
+	out, err := r.inner.Run(ctx, cmd)
+	fx := fixture{Name: cmd.Name, Args: cmd.Args, Output: out}
+	if err != nil {
+		fx.Err = err.Error()
+		fx.ExitCode = -1
+		var ee interface{ ExitCode() int } // *exec.ExitError, *replayError
+		if errors.As(err, &ee) {
+			fx.ExitCode = ee.ExitCode()
+		}
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, fx)
+	r.mu.Unlock()
+	return out, err
+}
+
+func (r *recordRunner) fact(key string, probe func() string) string {
+	v := probe()
+	r.mu.Lock()
+	if r.facts == nil {
+		r.facts = map[string]string{}
+	}
+	r.facts[key] = v
+	r.mu.Unlock()
+	return v
+}
+
+func (r *recordRunner) save(path string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.MarshalIndent(recording{GOOS: runtime.GOOS, Facts: r.facts, Commands: r.calls}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// replayRunner serves recorded fixtures. Repeated commands are answered in
+// recording order; the last answer is reused once a queue runs dry. Facts
+// missing from the recording are empty: the host is never probed.
+type replayRunner struct {
+	goos  string
+	facts map[string]string
+	mu    sync.Mutex
+	queue map[string][]fixture
+}
+
+func loadFixtures(path string) (*replayRunner, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec recording
+	if t := bytes.TrimSpace(data); len(t) > 0 && t[0] == '[' {
+		err = json.Unmarshal(data, &rec.Commands)
+	} else {
+		err = json.Unmarshal(data, &rec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r := &replayRunner{goos: rec.GOOS, facts: rec.Facts, queue: map[string][]fixture{}}
+	for _, fx := range rec.Commands {
+		r.queue[fx.key()] = append(r.queue[fx.key()], fx)
+	}
+	return r, nil
+}
+
+func (r *replayRunner) fact(key string, _ func() string) string { return r.facts[key] }
+
+func (r *replayRunner) Run(_ context.Context, cmd Command) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := fixture{Name: cmd.Name, Args: cmd.Args}.key()
+	q := r.queue[k]
+	if len(q) == 0 {
+		return "", errors.New(tr("runner.missing", cmd))
+	}
+	fx := q[0]
+	if len(q) > 1 {
+		r.queue[k] = q[1:]
+	}
+	switch {
+	case fx.Err == "":
+		return fx.Output, nil
+	case fx.Err == context.DeadlineExceeded.Error():
+		return fx.Output, context.DeadlineExceeded
+	default:
+		return fx.Output, &replayError{msg: fx.Err, code: fx.ExitCode}
+	}
+}
+
+// replayError stands in for *exec.ExitError when replaying.
+type replayError struct {
+	msg  string
+	code int
+}
+
+func (e *replayError) Error() string { return e.msg }
+func (e *replayError) ExitCode() int { return e.code }
+
+// Env switches for capturing a misbehaving workstation and reproducing it:
+//
+//	GETINFO_RECORD=run.json  record every command and its result
+//	GETINFO_REPLAY=run.json  serve commands from a previous recording
+//	GETINFO_ROOT=/fixture    read Linux /sys, /proc and /etc from this tree
+//	GETINFO_SMBIOS=dmi.bin   read the SMBIOS table from a captured dump
+//
+// A recording also keeps the SMBIOS table as run.json.smbios.bin, which
+// replay picks up.
+const (
+	envRecord = "GETINFO_RECORD"
+	envReplay = "GETINFO_REPLAY"
+	envRoot   = "GETINFO_ROOT"
+	envSMBIOS = "GETINFO_SMBIOS"
+)
+
+// runnerFromEnv picks the runner for this run and the platform whose
+// collectors use it. finish must be called at the end to flush a recording
+// (it is a no-op otherwise).
+func runnerFromEnv() (r Runner, plat *platform, finish func() error, err error) {
+	finish = func() error { return nil }
+	if p := strings.TrimSpace(Getenv(envReplay)); p != "" {
+		rr, err := loadFixtures(p)
+		if err != nil {
+			return nil, nil, finish, err
+		}
+		plat, err := platformFor(rr.goos)
+		if err != nil {
+			return nil, nil, finish, fmt.Errorf("%s: %w", p, err)
+		}
+		return rr, plat, finish, nil
+	}
+	if p := strings.TrimSpace(Getenv(envRecord)); p != "" {
+		rec := &recordRunner{inner: execRunner{}}
+		return rec, nativePlatform, func() error { return rec.save(p) }, nil
+	}
+	return execRunner{}, nativePlatform, finish, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replayCollector is what newRunCollector builds for GETINFO_REPLAY=path.
+func replayCollector(t *testing.T, path string) *collector {
+	t.Helper()
+	rr, err := loadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plat, err := platformFor(rr.goos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCollector()
+	c.runner = rr
+	c.plat = plat
+	c.now = time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	return c
+}
+
+// testdata/replay_windows.json is a workstation run; it must give the same
+// row on any OS.
+func TestReplayWindows(t *testing.T) {
+	c := replayCollector(t, filepath.Join("testdata", "replay_windows.json"))
+	if c.plat != windowsPlatform {
+		t.Fatalf("platform = %s, want windows", c.plat.goos)
+	}
+	cfg := defaultConfig()
+	got := collectAll(c, cfg.collectors(true), cfg.collectOptions())
+
+	want := map[string]string{
+		FieldSN.Key:        "5CG1234XYZ",
+		FieldUUID.Key:      "4C4C4544-0042-3510-8052-B7C04F4A3132",
+		FieldMGuid.Key:     "8a1c7f3e-52d4-4f0b-9c61-0d2e7b4a9f15",
+		FieldHost.Key:      "PC-FIN-042",
+		FieldUser.Key:      `contoso\jsilva`,
+		FieldMSTSC.Key:     "",
+		FieldIP.Key:        "10.20.30.41",
+		FieldWin.Key:       "10",
+		FieldCPU.Key:       "Intel(R) Core(TM) i5-10500 CPU @ 3.10GHz",
+		FieldRAM.Key:       "16",
+		FieldRAMType.Key:   "DDR4 2x8GB 2666",
+		FieldSlotUs.Key:    "2",
+		FieldSlotTot.Key:   "4",
+		FieldSlotLiv.Key:   "2",
+		FieldDisk.Key:      "476",
+		FieldLivre.Key:     "291",
+		FieldSSD.Key:       valueYes,
+		FieldDisks.Key:     "NVMe SSD 466GB; SATA HDD 932GB",
+		FieldGPU.Key:       valueYes,
+		FieldGPUModel.Key:  "NVIDIA GeForce GTX 1650; Intel(R) UHD Graphics 630",
+		FieldGPUVRAM.Key:   "4.0",
+		FieldGPUDriver.Key: "31.0.15.3623 (2023-08-02)",
+		FieldADID.Key:      "123456789",
+		FieldAV.Key:        "Windows Defender (off, up to date); Bitdefender Endpoint Security Tools Antimalware (on, up to date)",
+		FieldDefender.Key:  "passive, up to date",
+		FieldBD.Key:        "Bitdefender Endpoint Security Tools",
+		FieldData.Key:      "2026-10-18 09:30:00",
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %q, want %q", k, got[k], w)
+		}
+	}
+	if errs := c.errors(); len(errs) > 0 {
+		t.Errorf("errors: %q", errs)
+	}
+}
+
+// countRunner answers every command with its line and a call number.
+type countRunner struct{ n *int }
+
+func (r countRunner) Run(_ context.Context, cmd Command) (string, error) {
+	*r.n++
+	if cmd.Name == "fail" {
+		return "boom", errors.New("exit status 2")
+	}
+	return cmd.String() + " #" + strconv.Itoa(*r.n), nil
+}
+
+// stubRunner answers the command lines it knows; anything else fails like a
+// missing tool.
+type stubRunner map[string]string
+
+func (s stubRunner) Run(_ context.Context, cmd Command) (string, error) {
+	if out, ok := s[cmd.String()]; ok {
+		return out, nil
+	}
+	return "", &replayError{msg: "exit status 1", code: 1}
+}
+
+// A recording replays in call order, repeats its last answer once a queue
+// runs dry and never keeps the stdin of a command.
+func TestRecordReplayQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	n := 0
+	rec := &recordRunner{inner: countRunner{&n}}
+	c := &collector{runner: rec}
+	ver := Command{Name: "cmd", Args: []string{"/C", "ver"}}
+	c.run(ver)
+	c.run(ver)
+	c.run(Command{Name: "anydesk", Args: []string{"--set-password"}, Stdin: "segredo"})
+	c.run(Command{Name: "fail"})
+	if err := rec.save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "segredo") {
+		t.Fatalf("stdin recorded: %s", data)
+	}
+
+	rr, err := loadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = &collector{runner: rr}
+	for _, want := range []string{"cmd /C ver #1", "cmd /C ver #2", "cmd /C ver #2"} {
+		if out, err := c.run(ver); out != want || err != nil {
+			t.Errorf("ver = %q, %v; want %q", out, err, want)
+		}
+	}
+	out, err := c.run(Command{Name: "fail"})
+	var re *replayError
+	if out != "boom" || !errors.As(err, &re) || re.ExitCode() != -1 {
+		t.Errorf("fail = %q, %v", out, err)
+	}
+	if _, err := c.run(Command{Name: "hostname"}); err == nil {
+		t.Error("unrecorded command answered")
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	rec := &recordRunner{inner: stubRunner{"tool --a": "one"}}
+	ctx := context.Background()
+	rec.Run(ctx, Command{Name: "tool", Args: []string{"--a"}})
+	rec.Run(ctx, Command{Name: "tool", Args: []string{"--b"}})
+	if v := rec.fact("hostname", func() string { return "PC-01" }); v != "PC-01" {
+		t.Fatalf("fact = %q", v)
+	}
+	if err := rec.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	rr, err := loadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, err := rr.Run(ctx, Command{Name: "tool", Args: []string{"--a"}}); out != "one" || err != nil {
+		t.Errorf("--a = %q, %v", out, err)
+	}
+	_, err = rr.Run(ctx, Command{Name: "tool", Args: []string{"--b"}})
+	if e, ok := err.(*replayError); !ok || e.ExitCode() != 1 {
+		t.Errorf("--b err = %v", err)
+	}
+	if _, err := rr.Run(ctx, Command{Name: "tool", Args: []string{"--c"}}); err == nil {
+		t.Error("--c: no error for a command that was not recorded")
+	}
+	probed := false
+	if v := rr.fact("hostname", func() string { probed = true; return "CI" }); v != "PC-01" || probed {
+		t.Errorf("hostname = %q, probed %v", v, probed)
+	}
+	if v := rr.fact("env:SystemDrive", func() string { probed = true; return "C:" }); v != "" || probed {
+		t.Errorf("unrecorded fact = %q, probed %v", v, probed)
+	}
+}
+
+// Recordings from before facts were kept are a bare array of commands.
+func TestLoadFixturesArray(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.json")
+	os.WriteFile(path, []byte(`[{"name":"whoami","args":[],"output":"pc\\op","exitCode":0}]`), 0644)
+	rr, err := loadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rr.goos != "" {
+		t.Errorf("goos = %q", rr.goos)
+	}
+	if out, _ := rr.Run(context.Background(), Command{Name: "whoami"}); out != `pc\op` {
+		t.Errorf("out = %q", out)
+	}
+}
+
+func TestPlatformFor(t *testing.T) {
+	for _, goos := range []string{"", "windows"} {
+		if _, err := platformFor(goos); err != nil {
+			t.Errorf("%q: %v", goos, err)
+		}
+	}
+	if _, err := platformFor("plan9"); err == nil {
+		t.Error("plan9: no error")
+	}
+}
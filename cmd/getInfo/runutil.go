@@ -1,39 +1,55 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
 )
 
 func Getenv(k string) string { return os.Getenv(k) }
 
-// HideWindow avoids flashing console windows when calling tools.
-func runCmdTimeout(timeoutSec int, name string, args ...string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSec)*time.Second)
-	defer cancel()
-
-	var out bytes.Buffer
-	cmd := exec.CommandContext(ctx, name, args...)
-	cmd.Stdout, cmd.Stderr = &out, &out
-	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+func (c *collector) runCmdTimeout(timeoutSec int, name string, args ...string) (string, error) {
+	return c.run(Command{Name: name, Args: args, Timeout: time.Duration(timeoutSec) * time.Second})
+}
 
-	err := cmd.Run()
-	if ctx.Err() == context.DeadlineExceeded {
-		return strings.TrimSpace(out.String()), context.DeadlineExceeded
+func (c *collector) run(cmd Command) (string, error) {
+	r := c.runner
+	if r == nil {
+		r = execRunner{}
+	}
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
 	}
-	return strings.TrimSpace(out.String()), err
+	return r.Run(ctx, cmd)
+}
+
+// runCMD runs one line through the platform shell (cmd /C or sh -c).
+func (c *collector) runCMD(line string) (string, error) {
+	name, args := c.platform().shell(line)
+	return c.runCmdTimeout(6, name, args...)
 }
 
-func runCMD(line string) (string, error) { return runCmdTimeout(6, "cmd", "/C", line) }
+func windowsShell(line string) (string, []string) { return "cmd", []string{"/C", line} }
 
-func runPS(script string) (string, error) {
-	return runCmdTimeout(8, "powershell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", script)
+func (c *collector) runPS(script string) (string, error) {
+	return c.runCmdTimeout(8, "powershell", "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", script)
+}
+
+// getenv reads the environment of the machine being inventoried: the recorded
+// one when replaying.
+func (c *collector) getenv(k string) string {
+	return c.fact("env:"+k, func() string { return Getenv(k) })
+}
+
+// small env helper
+func (c *collector) getenvOr(k, def string) string {
+	if v := strings.TrimSpace(c.getenv(k)); v != "" {
+		return v
+	}
+	return def
 }
 
 func exeDir() string {
@@ -43,3 +59,21 @@ func exeDir() string {
 	}
 	return filepath.Dir(p)
 }
+
+func exeName() string { return filepath.Base(os.Args[0]) }
+
+func firstNonEmpty(vs ...string) string {
+	for _, v := range vs {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
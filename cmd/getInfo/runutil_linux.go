@@ -0,0 +1,8 @@
+package main
+
+import "os/exec"
+
+// No console windows to hide on Linux.
+func hideWindow(cmd *exec.Cmd) {}
+
+func linuxShell(line string) (string, []string) { return "sh", []string{"-c", line} }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Values typed by operators or taken from command output end up in files
+// people open in Excel: a leading = + - @ (or TAB/CR before one, which
+// Excel skips) turns a cell into a formula, and
+// control characters (newlines) break rows. Spreadsheet sinks (CSV, XLSX)
+// clean every field with output.sanitize before writing.
+const (
+	sanitizePrefix = "prefix" // formula: prepend '; control chars: space
+	sanitizeStrip  = "strip"  // drop leading formula chars and control chars
+	sanitizeReject = "reject" // leave the cell empty
+)
+
+type sanitizer struct{ policy string }
+
+// alteration is one cleaned field, for the report.
+type alteration struct {
+	field  Field
+	reason string // catalog key
+	before string
+	after  string
+}
+
+func isFormulaStart(r rune) bool {
+	return r == '=' || r == '+' || r == '-' || r == '@' || r == '\t' || r == '\r'
+}
+
+// plainNumber is what a signed value may look like and still be data, not
+// a formula: "-3", "+5.1". ParseFloat would also let "-Inf" and "+NaN" by.
+var plainNumber = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+
+// startsFormula looks at the raw value, before any cleaning.
+func startsFormula(v string) bool {
+	return v != "" && isFormulaStart([]rune(v)[0]) && !plainNumber.MatchString(v)
+}
+
+// unsafe reports why v needs cleaning, or "" when it does not.
+func unsafeValue(v string) string {
+	if startsFormula(v) {
+		return "sanitize.formula"
+	}
+	if strings.IndexFunc(v, unicode.IsControl) >= 0 {
+		return "sanitize.control"
+	}
+	return ""
+}
+
+func (s sanitizer) clean(v string) string {
+	switch s.policy {
+	case sanitizeReject:
+		return ""
+	case sanitizeStrip:
+		v = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, v)
+		return strings.TrimLeftFunc(v, func(r rune) bool { return isFormulaStart(r) || unicode.IsSpace(r) })
+	default:
+		formula := startsFormula(v)
+		v = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return ' '
+			}
+			return r
+		}, v)
+		if formula {
+			v = "'" + v
+		}
+		return v
+	}
+}
+
+// values returns a cleaned copy of vals and what was changed, in column
+// order.
+func (s sanitizer) values(vals Values) (Values, []alteration) {
+	out := make(Values, len(vals))
+	for k, v := range vals {
+		out[k] = v
+	}
+	var report []alteration
+	for _, f := range Fields() {
+		v := out[f.Key]
+		if reason := unsafeValue(v); reason != "" {
+			out[f.Key] = s.clean(v)
+			report = append(report, alteration{f, reason, v, out[f.Key]})
+		}
+	}
+	return out, report
+}
+
+// spreadsheetValues is what CSV and XLSX write for rec: cleaned, then with
+// the vocabulary applied (the configured words are trusted).
+func spreadsheetValues(rec *InventoryRecord, clean sanitizer, vocab VocabularyConfig) Values {
+	vals, _ := clean.values(rec.Values())
+	return vocab.render(vals)
+}
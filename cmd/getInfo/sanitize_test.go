@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestSanitize(t *testing.T) {
+	for _, c := range []struct {
+		in, reason, prefix, strip string
+	}{
+		{"PC-01", "", "PC-01", "PC-01"},
+		{"-3", "", "-3", "-3"},
+		{"+5.1", "", "+5.1", "+5.1"},
+		{"=HYPERLINK(\"x\")", "sanitize.formula", "'=HYPERLINK(\"x\")", "HYPERLINK(\"x\")"},
+		{"@SUM(A1)", "sanitize.formula", "'@SUM(A1)", "SUM(A1)"},
+		{"-Inf", "sanitize.formula", "'-Inf", "Inf"},
+		{"+NaN", "sanitize.formula", "'+NaN", "NaN"},
+		{"-1e3", "sanitize.formula", "'-1e3", "1e3"},
+		{"\t=1+1", "sanitize.formula", "' =1+1", "1+1"},
+		{"\r=cmd", "sanitize.formula", "' =cmd", "cmd"},
+		{"Sala 3\nbloco B", "sanitize.control", "Sala 3 bloco B", "Sala 3bloco B"},
+		{"\n=cmd", "sanitize.control", " =cmd", "cmd"},
+	} {
+		if got := unsafeValue(c.in); got != c.reason {
+			t.Errorf("unsafeValue(%q) = %q, want %q", c.in, got, c.reason)
+		}
+		if c.reason == "" {
+			continue
+		}
+		if got := (sanitizer{sanitizePrefix}).clean(c.in); got != c.prefix {
+			t.Errorf("prefix(%q) = %q, want %q", c.in, got, c.prefix)
+		}
+		if got := (sanitizer{sanitizeStrip}).clean(c.in); got != c.strip {
+			t.Errorf("strip(%q) = %q, want %q", c.in, got, c.strip)
+		}
+		if got := (sanitizer{sanitizeReject}).clean(c.in); got != "" {
+			t.Errorf("reject(%q) = %q", c.in, got)
+		}
+	}
+}
+
+// The report lists cleaned fields in column order and leaves the record
+// itself untouched.
+func TestSanitizeValues(t *testing.T) {
+	vals := Values{FieldHost.Key: "PC-01", FieldSN.Key: "=1+1", FieldNome.Key: "Ana\tSouza"}
+	out, report := (sanitizer{sanitizePrefix}).values(vals)
+	if out[FieldSN.Key] != "'=1+1" || out[FieldNome.Key] != "Ana Souza" || out[FieldHost.Key] != "PC-01" {
+		t.Errorf("values = %q", out)
+	}
+	if vals[FieldSN.Key] != "=1+1" {
+		t.Error("input changed")
+	}
+	if len(report) != 2 || report[0].field != FieldSN || report[1].field != FieldNome {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Sink is one output of a run. Every enabled sink gets the same record; a
+// failing sink is logged and does not stop the others.
+type Sink interface {
+	Name() string
+	Write(rec *InventoryRecord) error
+}
+
+// sinkWarning is a sink error where the data was still written: it is
+// logged but does not fail the run.
+type sinkWarning struct{ err error }
+
+func (w sinkWarning) Error() string { return w.err.Error() }
+func (w sinkWarning) Unwrap() error { return w.err }
+
+// sinkResult is one output's outcome, for the console summary.
+type sinkResult struct {
+	name string
+	err  error
+}
+
+// sinks returns the enabled outputs, in the order they are written.
+func (cfg *Config) sinks(base string, c *collector) []Sink {
+	dir := cfg.outputDir(base)
+	var out []Sink
+	if cfg.Output.CSV {
+		cs := csvSink{path: cfg.csvPath(base), log: c.addErr, lock: cfg.lockOptions(), dialect: cfg.csvDialect(), vocab: cfg.Vocabulary, clean: cfg.sanitizer()}
+		if cfg.Output.Mode == modeUpsert {
+			cs.upsert, cs.identity = true, cfg.Output.Identity
+			if cfg.Output.HistoryFile != "" {
+				cs.history = resolveIn(dir, cfg.Output.HistoryFile)
+			}
+		}
+		if cfg.Output.Spool {
+			out = append(out, spoolSink{inner: cs, dir: resolveIn(base, cfg.Output.SpoolDir), log: c.addErr})
+		} else {
+			out = append(out, cs)
+		}
+	}
+	if cfg.Output.Excel {
+		out = append(out, xlsxSink{path: resolveIn(dir, cfg.Output.ExcelFile), errs: c.errors, lock: cfg.lockOptions(), vocab: cfg.Vocabulary, clean: cfg.sanitizer()})
+	}
+	if cfg.Output.JSON {
+		out = append(out, jsonSink{dir: resolveIn(dir, cfg.Output.JSONDir)})
+	}
+	if cfg.Output.NDJSON {
+		out = append(out, ndjsonSink{path: resolveIn(dir, cfg.Output.NDJSONFile)})
+	}
+	// Last: local files are written even when the network is down.
+	if cfg.HTTP.Enabled {
+		h := cfg.HTTP
+		for _, p := range []*string{&h.CAFile, &h.ClientCert, &h.ClientKey} {
+			if *p != "" {
+				*p = resolveIn(base, *p)
+			}
+		}
+		if cfg.Output.Spool {
+			out = append(out, spoolSink{inner: httpSink{cfg: h}, dir: resolveIn(base, filepath.Join(cfg.Output.SpoolDir, httpSpoolDir)), log: c.addErr, queueIf: httpRetryable})
+		} else {
+			out = append(out, httpSink{cfg: h})
+		}
+	}
+	return out
+}
+
+func resolveIn(dir, p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// jsonSink keeps one file per machine with its latest record.
+type jsonSink struct{ dir string }
+
+func (s jsonSink) Name() string { return "json" }
+
+func (s jsonSink) Write(rec *InventoryRecord) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, machineFileName(rec)+".json")
+	// Write aside and rename so readers never see a half-written file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ndjsonSink appends one compact JSON line per run.
+type ndjsonSink struct{ path string }
+
+func (s ndjsonSink) Name() string { return "ndjson" }
+
+func (s ndjsonSink) Write(rec *InventoryRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// machineFileName is "<host>_<id>" where id is the first of SN, UUID and
+// MachineGuid that is set, reduced to characters safe in file names.
+func machineFileName(rec *InventoryRecord) string {
+	var parts []string
+	if h := safeFileChars(rec.Host); h != "" {
+		parts = append(parts, h)
+	}
+	if id := safeFileChars(firstNonEmpty(rec.SN, rec.UUID, rec.MachineGuid)); id != "" {
+		parts = append(parts, id)
+	}
+	if len(parts) == 0 {
+		return rec.RunID
+	}
+	return strings.Join(parts, "_")
+}
+
+func safeFileChars(s string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	return strings.Trim(b.String(), "-.")
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMachineFileName(t *testing.T) {
+	for _, c := range []struct {
+		rec  InventoryRecord
+		want string
+	}{
+		{InventoryRecord{Host: "PC-01", SN: "7XK3Q93", UUID: "U"}, "PC-01_7XK3Q93"},
+		{InventoryRecord{Host: "PC 01", UUID: "4C4C4544-0058"}, "PC-01_4C4C4544-0058"},
+		{InventoryRecord{Host: "..", SN: "To Be Filled/O.E.M."}, "To-Be-Filled-O.E.M"},
+		{InventoryRecord{RunID: "run-1"}, "run-1"},
+	} {
+		if got := machineFileName(&c.rec); got != c.want {
+			t.Errorf("machineFileName(%+v) = %q, want %q", c.rec, got, c.want)
+		}
+	}
+}
+
+// The JSON sink keeps the latest record per machine; NDJSON keeps every run.
+func TestJSONSinks(t *testing.T) {
+	dir := t.TempDir()
+	js := jsonSink{dir: filepath.Join(dir, "maquinas")}
+	nd := ndjsonSink{path: filepath.Join(dir, "runs", "inventario.ndjson")}
+	for _, run := range []string{"run-1", "run-2"} {
+		rec := newRecord(Values{FieldHost.Key: "PC-01", FieldSN.Key: "7XK3Q93"}, run)
+		for _, s := range []Sink{js, nd} {
+			if err := s.Write(rec); err != nil {
+				t.Fatalf("%s: %v", s.Name(), err)
+			}
+		}
+	}
+
+	files, _ := os.ReadDir(js.dir)
+	if len(files) != 1 || files[0].Name() != "PC-01_7XK3Q93.json" {
+		t.Fatalf("json dir: %v", files)
+	}
+	var rec InventoryRecord
+	data, _ := os.ReadFile(filepath.Join(js.dir, files[0].Name()))
+	if err := json.Unmarshal(data, &rec); err != nil || rec.RunID != "run-2" {
+		t.Errorf("json file: %v, %s", err, data)
+	}
+
+	data, _ = os.ReadFile(nd.path)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"runId":"run-1"`) || !strings.Contains(lines[1], `"runId":"run-2"`) {
+		t.Errorf("ndjson:\n%s", data)
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Pure-Go reader for the SMBIOS structure table, where the firmware keeps
+// serial, UUID and memory layout. One read of the table replaces several
+// PowerShell/WMIC round trips; the commands stay as fallbacks.
+//
+// Input is always the RawSMBIOSData layout Windows' GetSystemFirmwareTable
+// ('RSMB') returns: an 8-byte header (calling method, major, minor, DMI
+// revision, table length as uint32 LE) followed by the table. Linux readers
+// and captured dumps use the same layout.
+
+const rawSMBIOSHeaderLen = 8
+
+type smbiosStruct struct {
+	typ     uint8
+	handle  uint16
+	data    []byte   // formatted area, header included, so offsets match the spec
+	strings []string // string-set; index 1 is strings[0]
+}
+
+func (s smbiosStruct) byteAt(off int) (uint8, bool) {
+	if off < len(s.data) {
+		return s.data[off], true
+	}
+	return 0, false
+}
+
+func (s smbiosStruct) word(off int) (uint16, bool) {
+	if off+2 <= len(s.data) {
+		return binary.LittleEndian.Uint16(s.data[off:]), true
+	}
+	return 0, false
+}
+
+func (s smbiosStruct) dword(off int) (uint32, bool) {
+	if off+4 <= len(s.data) {
+		return binary.LittleEndian.Uint32(s.data[off:]), true
+	}
+	return 0, false
+}
+
+// str resolves the string-number at off ("" when absent).
+func (s smbiosStruct) str(off int) string {
+	n, ok := s.byteAt(off)
+	if !ok || n == 0 || int(n) > len(s.strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.strings[n-1])
+}
+
+// parseSMBIOSTable splits the table into structures, stopping at type 127.
+func parseSMBIOSTable(table []byte) ([]smbiosStruct, error) {
+	var out []smbiosStruct
+	for off := 0; off+4 <= len(table); {
+		typ, length := table[off], int(table[off+1])
+		if length < 4 || off+length > len(table) {
+			return out, trErr("smbios.truncated", typ, off)
+		}
+		s := smbiosStruct{
+			typ:    typ,
+			handle: binary.LittleEndian.Uint16(table[off+2:]),
+			data:   table[off : off+length],
+		}
+		// String-set: NUL-terminated strings, ended by an extra NUL.
+		p := off + length
+		for {
+			end := p
+			for end < len(table) && table[end] != 0 {
+				end++
+			}
+			if end >= len(table) {
+				return out, trErr("smbios.truncated", typ, p)
+			}
+			if end == p {
+				// Empty string: the terminator (or the first NUL of an empty set).
+				if len(s.strings) == 0 && end+1 < len(table) && table[end+1] == 0 {
+					end++
+				}
+				p = end + 1
+				break
+			}
+			s.strings = append(s.strings, string(table[p:end]))
+			p = end + 1
+		}
+		out = append(out, s)
+		if typ == 127 {
+			break
+		}
+		off = p
+	}
+	return out, nil
+}
+
+// Decoded structures (types 0, 1, 2, 3, 4, 16, 17). Numbers that the
+// firmware reports as unknown are 0.
+type smbiosInfo struct {
+	major, minor uint8
+
+	BIOSVendor, BIOSVersion, BIOSDate string // type 0
+
+	Manufacturer, Product, SystemVersion, Serial, UUID, SKU, Family string // type 1
+
+	BoardManufacturer, BoardProduct, BoardSerial string // type 2
+
+	ChassisType                                  uint8 // type 3 (3 = desktop, 9/10 = laptop/notebook...)
+	ChassisManufacturer, ChassisSerial, AssetTag string
+
+	Processors    []smbiosProcessor    // type 4
+	MemoryArrays  []smbiosMemoryArray  // type 16
+	MemoryDevices []smbiosMemoryDevice // type 17
+}
+
+type smbiosProcessor struct {
+	Socket, Manufacturer, Version string
+	Populated                     bool
+	MaxSpeedMHz                   int
+	Cores, Threads                int
+}
+
+type smbiosMemoryArray struct {
+	handle     uint16
+	Use        uint8 // 3 = system memory
+	Devices    int   // number of slots
+	MaxCapacKB uint64
+}
+
+type smbiosMemoryDevice struct {
+	arrayHandle          uint16
+	Locator, BankLocator string
+	SizeMB               int64 // 0 = empty slot
+	FormFactor           uint8
+	Type                 uint8
+	SpeedMTs             int // rated
+	ConfiguredMTs        int // running
+	Manufacturer         string
+	Serial, PartNumber   string
+}
+
+// parseRawSMBIOS decodes a RawSMBIOSData blob. A table cut short still gives
+// the structures before the break, along with the error.
+func parseRawSMBIOS(raw []byte) (*smbiosInfo, error) {
+	if len(raw) < rawSMBIOSHeaderLen {
+		return nil, trErr("smbios.short", len(raw), rawSMBIOSHeaderLen)
+	}
+	n := int(binary.LittleEndian.Uint32(raw[4:]))
+	table := raw[rawSMBIOSHeaderLen:]
+	if n > len(table) {
+		return nil, trErr("smbios.short", len(table), n)
+	}
+	structs, err := parseSMBIOSTable(table[:n])
+	if len(structs) == 0 && err != nil {
+		return nil, err
+	}
+	info := &smbiosInfo{major: raw[1], minor: raw[2]}
+	for _, s := range structs {
+		info.add(s)
+	}
+	return info, err
+}
+
+func (info *smbiosInfo) atLeast(major, minor uint8) bool {
+	return info.major > major || (info.major == major && info.minor >= minor)
+}
+
+func (info *smbiosInfo) add(s smbiosStruct) {
+	switch s.typ {
+	case 0:
+		info.BIOSVendor, info.BIOSVersion, info.BIOSDate = s.str(0x04), s.str(0x05), s.str(0x08)
+	case 1:
+		info.Manufacturer, info.Product = s.str(0x04), s.str(0x05)
+		info.SystemVersion, info.Serial = s.str(0x06), s.str(0x07)
+		if len(s.data) >= 0x18 {
+			info.UUID = formatSMBIOSUUID(s.data[0x08:0x18], info.atLeast(2, 6))
+		}
+		info.SKU, info.Family = s.str(0x19), s.str(0x1A)
+	case 2:
+		info.BoardManufacturer, info.BoardProduct, info.BoardSerial = s.str(0x04), s.str(0x05), s.str(0x07)
+	case 3:
+		t, _ := s.byteAt(0x05)
+		info.ChassisType = t & 0x7F
+		info.ChassisManufacturer, info.ChassisSerial, info.AssetTag = s.str(0x04), s.str(0x07), s.str(0x08)
+	case 4:
+		p := smbiosProcessor{Socket: s.str(0x04), Manufacturer: s.str(0x07), Version: s.str(0x10)}
+		if st, ok := s.byteAt(0x18); ok {
+			p.Populated = st&0x40 != 0
+		}
+		if v, ok := s.word(0x14); ok {
+			p.MaxSpeedMHz = int(v)
+		}
+		if v, ok := s.byteAt(0x23); ok {
+			p.Cores = int(v)
+		}
+		if v, ok := s.byteAt(0x25); ok {
+			p.Threads = int(v)
+		}
+		// 0xFF means "see the 3.0 word fields".
+		if v, ok := s.word(0x2A); ok && p.Cores == 0xFF {
+			p.Cores = int(v)
+		}
+		if v, ok := s.word(0x2E); ok && p.Threads == 0xFF {
+			p.Threads = int(v)
+		}
+		info.Processors = append(info.Processors, p)
+	case 16:
+		a := smbiosMemoryArray{handle: s.handle}
+		a.Use, _ = s.byteAt(0x05)
+		if v, ok := s.word(0x0D); ok {
+			a.Devices = int(v)
+		}
+		if v, ok := s.dword(0x07); ok {
+			a.MaxCapacKB = uint64(v)
+			if v == 0x80000000 && len(s.data) >= 0x17 {
+				a.MaxCapacKB = binary.LittleEndian.Uint64(s.data[0x0F:]) / 1024
+			}
+		}
+		info.MemoryArrays = append(info.MemoryArrays, a)
+	case 17:
+		d := smbiosMemoryDevice{
+			Locator:      s.str(0x10),
+			BankLocator:  s.str(0x11),
+			Manufacturer: s.str(0x17),
+			Serial:       s.str(0x18),
+			PartNumber:   s.str(0x1A),
+		}
+		d.arrayHandle, _ = s.word(0x04)
+		d.FormFactor, _ = s.byteAt(0x0E)
+		d.Type, _ = s.byteAt(0x12)
+		if v, ok := s.word(0x0C); ok {
+			switch {
+			case v == 0xFFFF: // unknown
+			case v == 0x7FFF:
+				if ext, ok := s.dword(0x1C); ok {
+					d.SizeMB = int64(ext & 0x7FFFFFFF)
+				}
+			case v&0x8000 != 0: // KB granularity
+				d.SizeMB = int64(v&0x7FFF) / 1024
+			default:
+				d.SizeMB = int64(v)
+			}
+		}
+		if v, ok := s.word(0x15); ok {
+			d.SpeedMTs = int(v)
+		}
+		if v, ok := s.word(0x20); ok {
+			d.ConfiguredMTs = int(v)
+		}
+		// 0xFFFF: the 3.3 dword fields hold the value.
+		if v, ok := s.dword(0x54); ok && d.SpeedMTs == 0xFFFF {
+			d.SpeedMTs = int(v)
+		}
+		if v, ok := s.dword(0x58); ok && d.ConfiguredMTs == 0xFFFF {
+			d.ConfiguredMTs = int(v)
+		}
+		info.MemoryDevices = append(info.MemoryDevices, d)
+	}
+}
+
+// formatSMBIOSUUID prints the UUID the way Windows (Win32_ComputerSystemProduct)
+// does. Since 2.6 the first three fields are little-endian. All-0 and all-FF
+// mean "not set".
+func formatSMBIOSUUID(b []byte, littleEndian bool) string {
+	allZero, allFF := true, true
+	for _, x := range b {
+		allZero = allZero && x == 0x00
+		allFF = allFF && x == 0xFF
+	}
+	if allZero || allFF {
+		return ""
+	}
+	u := append([]byte(nil), b...)
+	if littleEndian {
+		u[0], u[1], u[2], u[3] = u[3], u[2], u[1], u[0]
+		u[4], u[5] = u[5], u[4]
+		u[6], u[7] = u[7], u[6]
+	}
+	return fmt.Sprintf("%X-%X-%X-%X-%X", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// memorySlots counts slots in system-memory arrays (falling back to the
+// type 17 entries) and the populated ones.
+func (info *smbiosInfo) memorySlots() (used, total int64, ok bool) {
+	system := map[uint16]bool{}
+	for _, a := range info.MemoryArrays {
+		if a.Use == 0x03 {
+			system[a.handle] = true
+			total += int64(a.Devices)
+		}
+	}
+	var devices int64
+	for _, d := range info.MemoryDevices {
+		if len(system) > 0 && !system[d.arrayHandle] {
+			continue
+		}
+		devices++
+		if d.SizeMB > 0 {
+			used++
+		}
+	}
+	if total == 0 {
+		total = devices
+	}
+	return used, total, total > 0
+}
+
+// smbiosSource reads and decodes the table once per run; collectors running
+// in parallel share it (see collector.smbiosSrc).
+type smbiosSource struct {
+	once    sync.Once
+	load    func() ([]byte, error) // RawSMBIOSData
+	save    string                 // GETINFO_RECORD: keep a copy of the blob here
+	info    *smbiosInfo
+	err     error
+	saveErr error
+	logOnce sync.Once
+}
+
+// newSMBIOSSource picks where the table comes from: a captured dump
+// (GETINFO_SMBIOS, or the one next to a GETINFO_REPLAY recording) or the
+// firmware itself.
+func newSMBIOSSource(root string) *smbiosSource {
+	if p := strings.TrimSpace(Getenv(envSMBIOS)); p != "" {
+		return &smbiosSource{load: readSMBIOSDump(p)}
+	}
+	if p := strings.TrimSpace(Getenv(envReplay)); p != "" {
+		// Recordings without a dump replay the command fallbacks as before.
+		return &smbiosSource{load: readSMBIOSDump(p + smbiosDumpSuffix)}
+	}
+	s := &smbiosSource{load: func() ([]byte, error) { return readSMBIOS(root) }}
+	if p := strings.TrimSpace(Getenv(envRecord)); p != "" {
+		s.save = p + smbiosDumpSuffix
+	}
+	return s
+}
+
+func (s *smbiosSource) get() (*smbiosInfo, error) {
+	s.once.Do(func() {
+		raw, err := s.load()
+		if err != nil {
+			s.err = err
+			return
+		}
+		if s.save != "" {
+			s.saveErr = os.WriteFile(s.save, raw, 0644)
+		}
+		s.info, s.err = parseRawSMBIOS(raw)
+	})
+	return s.info, s.err
+}
+
+// smbios returns the decoded table (nil when unavailable, partial when the
+// table was cut short); problems are logged once per run, the callers just
+// fall back to their commands.
+func (c *collector) smbios() *smbiosInfo {
+	if c.smbiosSrc == nil {
+		return nil
+	}
+	info, err := c.smbiosSrc.get()
+	c.smbiosSrc.logOnce.Do(func() {
+		c.addErr("smbios", err, "")
+		c.addErr("smbios", c.smbiosSrc.saveErr, c.smbiosSrc.save)
+	})
+	return info
+}
+
+// smbiosDumpSuffix is appended to GETINFO_RECORD/GETINFO_REPLAY paths for the
+// table captured alongside the command fixtures.
+const smbiosDumpSuffix = ".smbios.bin"
+
+func readSMBIOSDump(path string) func() ([]byte, error) {
+	return func() ([]byte, error) { return os.ReadFile(path) }
+}
+
+// wrapRawSMBIOS builds the RawSMBIOSData header around a bare table (Linux).
+func wrapRawSMBIOS(major, minor uint8, table []byte) []byte {
+	raw := make([]byte, rawSMBIOSHeaderLen, rawSMBIOSHeaderLen+len(table))
+	raw[1], raw[2] = major, minor
+	binary.LittleEndian.PutUint32(raw[4:], uint32(len(table)))
+	return append(raw, table...)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// readSMBIOS returns the kernel's copy of the table (root only) in the
+// RawSMBIOSData layout, taking the version from the entry point.
+func readSMBIOS(root string) ([]byte, error) {
+	dir := filepath.Join(root, "sys", "firmware", "dmi", "tables")
+	ep, err := os.ReadFile(filepath.Join(dir, "smbios_entry_point"))
+	if err != nil {
+		return nil, err
+	}
+	var major, minor uint8
+	switch {
+	case bytes.HasPrefix(ep, []byte("_SM3_")) && len(ep) > 8:
+		major, minor = ep[7], ep[8]
+	case bytes.HasPrefix(ep, []byte("_SM_")) && len(ep) > 7:
+		major, minor = ep[6], ep[7]
+	default:
+		return nil, trErr("smbios.version", filepath.Join(dir, "smbios_entry_point"))
+	}
+	table, err := os.ReadFile(filepath.Join(dir, "DMI"))
+	if err != nil {
+		return nil, err
+	}
+	return wrapRawSMBIOS(major, minor, table), nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readTestDump(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// desktop_v32: OptiPlex, SMBIOS 3.2, 4 slots with 2x16GB and a video-memory
+// array that is not system RAM.
+func TestParseSMBIOSDesktop(t *testing.T) {
+	info, err := parseRawSMBIOS(readTestDump(t, "desktop_v32.smbios.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ name, got, want string }{
+		{"serial", info.Serial, "7XK3Q93"},
+		{"uuid", info.UUID, "4C4C4544-0058-4B10-8033-B7C04F513933"},
+		{"product", info.Product, "OptiPlex 7080"},
+		{"bios", info.BIOSVersion, "1.21.0"},
+		{"board serial", info.BoardSerial, "/7XK3Q93/CNFCW0012345AB/"},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %q, want %q", c.name, c.got, c.want)
+		}
+	}
+	if len(info.Processors) != 1 || info.Processors[0].Cores != 8 || info.Processors[0].Threads != 16 {
+		t.Errorf("processors = %+v", info.Processors)
+	}
+	if used, total, ok := info.memorySlots(); used != 2 || total != 4 || !ok {
+		t.Errorf("slots = %d/%d %v", used, total, ok)
+	}
+	mods := info.memoryModules()
+	if len(mods) != 4 || mods[1].SizeMB != 0 || mods[2].PartNumber != "HMA82GU6CJR8N-XN" {
+		t.Fatalf("modules = %+v", mods)
+	}
+	if s := memorySummary(mods); s != "DDR4 2x16GB 2933" {
+		t.Errorf("summary = %q", s)
+	}
+}
+
+// legacy_v24: board-builder firmware, SMBIOS 2.4 (UUID stored big-endian),
+// placeholder strings and short type 17 entries without configured speed.
+func TestParseSMBIOSLegacy(t *testing.T) {
+	info, err := parseRawSMBIOS(readTestDump(t, "legacy_v24.smbios.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.UUID != "03000200-0400-0500-0006-000700080009" {
+		t.Errorf("uuid = %q", info.UUID)
+	}
+	if info.Serial != "To Be Filled By O.E.M." {
+		t.Errorf("serial = %q", info.Serial)
+	}
+	if used, total, _ := info.memorySlots(); used != 2 || total != 2 {
+		t.Errorf("slots = %d/%d", used, total)
+	}
+	if s := memorySummary(info.memoryModules()); s != "DDR2 2x1GB 800" {
+		t.Errorf("summary = %q", s)
+	}
+}
+
+// A table cut inside a structure keeps what came before and reports the cut.
+func TestParseSMBIOSTruncated(t *testing.T) {
+	raw := readTestDump(t, "desktop_v32.smbios.bin")
+	cut := len(raw) - 120 // inside the last type 17 entries
+	raw = append([]byte(nil), raw[:cut]...)
+	binary.LittleEndian.PutUint32(raw[4:], uint32(cut-rawSMBIOSHeaderLen))
+
+	info, err := parseRawSMBIOS(raw)
+	if err == nil {
+		t.Fatal("no error for a truncated table")
+	}
+	if info == nil || info.Serial != "7XK3Q93" || len(info.MemoryDevices) == 0 {
+		t.Fatalf("info = %+v", info)
+	}
+
+	src := &smbiosSource{load: func() ([]byte, error) { return raw, nil }}
+	if ck := checkSMBIOS(src, false); ck.ok {
+		t.Errorf("doctor passes a truncated table: %q", ck.info)
+	}
+
+	if _, err := parseRawSMBIOS(raw[:4]); err == nil {
+		t.Error("no error for a short header")
+	}
+}
+
+func TestSMBIOSSourceError(t *testing.T) {
+	missing := errors.New("no table")
+	src := &smbiosSource{load: func() ([]byte, error) { return nil, missing }}
+	if info, err := src.get(); info != nil || err != missing {
+		t.Errorf("get = %v, %v", info, err)
+	}
+	if ck := checkSMBIOS(src, false); ck.ok {
+		t.Error("doctor passes a missing table")
+	}
+}
+
+// Without root a permission error is expected on Linux and not a failure.
+func TestSMBIOSCheckNeedsRoot(t *testing.T) {
+	denied := &fs.PathError{Op: "open", Path: "DMI", Err: fs.ErrPermission}
+	src := &smbiosSource{load: func() ([]byte, error) { return nil, denied }}
+	if ck := checkSMBIOS(src, false); !ck.ok || ck.info != tr("doctor.noroot", denied) {
+		t.Errorf("not root: %+v", ck)
+	}
+	if ck := checkSMBIOS(src, true); ck.ok {
+		t.Errorf("root: %+v", ck)
+	}
+}
+
+// Linux reads the bare table from sysfs and wraps it like Windows returns it.
+func TestWrapRawSMBIOS(t *testing.T) {
+	raw := readTestDump(t, "desktop_v32.smbios.bin")
+	wrapped := wrapRawSMBIOS(raw[1], raw[2], raw[rawSMBIOSHeaderLen:])
+	if string(wrapped) != string(raw) {
+		t.Error("wrapped table differs from the dump")
+	}
+}
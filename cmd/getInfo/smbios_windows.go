@@ -0,0 +1,38 @@
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetSystemFirmwareTable = syscall.NewLazyDLL("kernel32.dll").NewProc("GetSystemFirmwareTable")
+
+// 'RSMB': the raw SMBIOS provider; no administrator rights needed.
+const firmwareRSMB = 0x52534D42
+
+// readSMBIOS returns the RawSMBIOSData blob (root is unused on Windows).
+func readSMBIOS(string) ([]byte, error) {
+	n, _, err := procGetSystemFirmwareTable.Call(firmwareRSMB, 0, 0, 0)
+	if n == 0 {
+		return nil, firmwareErr(err)
+	}
+	buf := make([]byte, n)
+	got, _, err := procGetSystemFirmwareTable.Call(firmwareRSMB, 0, uintptr(unsafe.Pointer(&buf[0])), n)
+	if got == 0 {
+		return nil, firmwareErr(err)
+	}
+	if got > n {
+		return nil, trErr("smbios.short", n, got)
+	}
+	return buf[:got], nil
+}
+
+// firmwareErr turns the last error of a failed call into a real error; Call
+// hands back errno 0 ("operation completed successfully") when the firmware
+// just has no table.
+func firmwareErr(err error) error {
+	if errno, ok := err.(syscall.Errno); ok && errno != 0 {
+		return errno
+	}
+	return trErr("smbios.empty")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// spoolSink protects a sink (the main CSV, the HTTP upload) against being
+// locked by Excel or unreachable: a row that cannot be written is saved as
+// one JSON file in a local spool directory and written, in order, by a later
+// run.
+type spoolSink struct {
+	inner Sink
+	dir   string
+	log   func(ctx string, err error, detail string)
+	// queueIf limits queuing to failures a later run can fix; nil = all.
+	queueIf func(error) bool
+}
+
+func (s spoolSink) Name() string { return s.inner.Name() }
+
+func (s spoolSink) Write(rec *InventoryRecord) error {
+	pending, drainErr := s.drain()
+	if drainErr == nil {
+		err := s.inner.Write(rec)
+		if err == nil {
+			return nil
+		}
+		var warn sinkWarning
+		if errors.As(err, &warn) {
+			return err // written anyway
+		}
+		if s.queueIf != nil && !s.queueIf(err) {
+			return err
+		}
+		drainErr = err
+	}
+	// Older rows still queued (or this write failed): queue behind them so
+	// the file keeps run order.
+	p, err := s.enqueue(rec)
+	if err != nil {
+		return trErr("spool.failed", drainErr, err)
+	}
+	return sinkWarning{trErr("spool.queued", drainErr, p, pending+1)}
+}
+
+// drain writes queued rows oldest first and stops at the first failure a
+// later run can fix, returning how many are still pending.
+func (s spoolSink) drain() (int, error) {
+	files, err := s.pending()
+	if err != nil {
+		return 0, err
+	}
+	for i, p := range files {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return len(files) - i, err
+		}
+		var rec InventoryRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			// Unreadable entry: set it aside instead of blocking the queue.
+			_ = os.Rename(p, p+".invalido")
+			s.log("spool", err, p)
+			continue
+		}
+		if err := s.inner.Write(&rec); err != nil {
+			var warn sinkWarning
+			switch {
+			case errors.As(err, &warn):
+				s.log("spool", err, p)
+			case s.queueIf != nil && !s.queueIf(err):
+				// Rejected for good (e.g. a 4xx): retrying cannot help, so set
+				// it aside like an unreadable entry and keep draining.
+				_ = os.Rename(p, p+".rejeitado")
+				s.log("spool", err, p)
+				continue
+			default:
+				return len(files) - i, err
+			}
+		}
+		if err := os.Remove(p); err != nil {
+			// Written but not removed would be written twice: stop here.
+			return len(files) - i - 1, err
+		}
+		s.log("spool", errors.New(tr("spool.drained")), filepath.Base(p))
+	}
+	return 0, nil
+}
+
+func (s spoolSink) pending() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			files = append(files, filepath.Join(s.dir, e.Name()))
+		}
+	}
+	sort.Strings(files) // names start with a sortable timestamp
+	return files, nil
+}
+
+func (s spoolSink) enqueue(rec *InventoryRecord) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "_" + rec.RunID + ".json"
+	p := filepath.Join(s.dir, name)
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return "", err
+	}
+	return p, os.Rename(tmp, p)
+}
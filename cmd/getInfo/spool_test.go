@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// memSink records run IDs, or fails with err while it is set.
+type memSink struct {
+	err  *error
+	runs *[]string
+}
+
+func (s memSink) Name() string { return "mem" }
+
+func (s memSink) Write(rec *InventoryRecord) error {
+	if *s.err != nil {
+		return *s.err
+	}
+	*s.runs = append(*s.runs, rec.RunID)
+	return nil
+}
+
+// Rows that could not be written wait in the spool and go out in run order,
+// ahead of the next row.
+func TestSpoolKeepsOrder(t *testing.T) {
+	var runs []string
+	failing := error(errors.New("arquivo em uso"))
+	dir := t.TempDir()
+	s := spoolSink{inner: memSink{&failing, &runs}, dir: filepath.Join(dir, "fila"), log: func(string, error, string) {}}
+
+	var warn sinkWarning
+	for _, id := range []string{"run-1", "run-2"} {
+		if err := s.Write(&InventoryRecord{RunID: id}); !errors.As(err, &warn) {
+			t.Fatalf("%s: err = %v, want a warning", id, err)
+		}
+	}
+	if n, _ := s.pending(); len(n) != 2 {
+		t.Fatalf("%d queued", len(n))
+	}
+
+	failing = nil
+	if err := s.Write(&InventoryRecord{RunID: "run-3"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(runs, []string{"run-1", "run-2", "run-3"}) {
+		t.Errorf("written %q", runs)
+	}
+	if n, _ := s.pending(); len(n) != 0 {
+		t.Errorf("%d still queued", len(n))
+	}
+}
+
+// An entry that is not a record is set aside instead of blocking the queue.
+func TestSpoolInvalidEntry(t *testing.T) {
+	var runs []string
+	var ok error
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "20240101T000000.000000000_x.json")
+	os.WriteFile(bad, []byte("{"), 0644)
+	var logged []string
+	s := spoolSink{inner: memSink{&ok, &runs}, dir: dir, log: func(ctx string, err error, detail string) { logged = append(logged, detail) }}
+	if err := s.Write(&InventoryRecord{RunID: "run-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(bad + ".invalido"); err != nil || !slices.Equal(runs, []string{"run-1"}) {
+		t.Errorf("set aside: %v, written %q", err, runs)
+	}
+	if !slices.Contains(logged, bad) {
+		t.Errorf("logged %q", logged)
+	}
+}
+
+// An entry the sink rejects for good is set aside; the rows behind it still
+// go out.
+func TestSpoolRejectedEntry(t *testing.T) {
+	var runs []string
+	var ok error
+	dir := t.TempDir()
+	rejected := errors.New("http 400")
+	var logged []string
+	s := spoolSink{
+		inner:   rejectSink{memSink{&ok, &runs}, "run-1", rejected},
+		dir:     dir,
+		log:     func(ctx string, err error, detail string) { logged = append(logged, detail) },
+		queueIf: func(err error) bool { return err != rejected },
+	}
+	first, err := s.enqueue(&InventoryRecord{RunID: "run-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.enqueue(&InventoryRecord{RunID: "run-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(&InventoryRecord{RunID: "run-3"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(runs, []string{"run-2", "run-3"}) {
+		t.Errorf("written %q", runs)
+	}
+	if _, err := os.Stat(first + ".rejeitado"); err != nil {
+		t.Errorf("set aside: %v", err)
+	}
+	if !slices.Contains(logged, first) {
+		t.Errorf("logged %q", logged)
+	}
+	if n, _ := s.pending(); len(n) != 0 {
+		t.Errorf("%d still queued", len(n))
+	}
+}
+
+// rejectSink fails with err for one run ID and passes the rest on.
+type rejectSink struct {
+	memSink
+	runID string
+	err   error
+}
+
+func (s rejectSink) Write(rec *InventoryRecord) error {
+	if rec.RunID == s.runID {
+		return s.err
+	}
+	return s.memSink.Write(rec)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+var errNoIdentity error = msgError("upsert.no_identity")
+
+// upsertRow keeps one row per machine: rows with the same identity are
+// replaced in place (at the first match), First_Seen is carried over, and
+// the superseded rows are appended to the history file.
+func (s csvSink) upsertRow(rec *InventoryRecord) error {
+	// Write already created/migrated the file.
+	oldHeader, rows, err := readInventoryCSV(s.path, s.dialect)
+	if err != nil {
+		return trErr("file.read", s.path, err)
+	}
+	header := mergeHeader(oldHeader, Headers())
+	for i := range rows {
+		rows[i] = remapRow(rows[i], oldHeader, header)
+	}
+
+	newRow := remapRow(Row(spreadsheetValues(rec, s.clean, s.vocab)), Headers(), header)
+	key, err := identityKey(newRow, header, s.identity)
+	if err != nil {
+		// Unidentifiable machine: keep its data, just don't merge it.
+		rows = append(rows, newRow)
+		if werr := writeCSVFile(s.path, header, rows, s.dialect); werr != nil {
+			return werr
+		}
+		return sinkWarning{trErr("upsert.appended", s.path, err, strings.Join(s.identity, "+"))}
+	}
+
+	col := columnIndex(header)
+	var kept, superseded [][]string
+	pos := -1
+	for _, r := range rows {
+		if k, err := identityKey(r, header, s.identity); err == nil && k == key {
+			if pos < 0 {
+				pos = len(kept)
+			}
+			superseded = append(superseded, r)
+			continue
+		}
+		kept = append(kept, r)
+	}
+
+	if first := earliestSeen(superseded, col); first != "" {
+		newRow[col[FieldFirstSeen.Header]] = first
+	}
+	if pos < 0 {
+		kept = append(kept, newRow)
+	} else {
+		kept = append(kept[:pos], append([][]string{newRow}, kept[pos:]...)...)
+	}
+
+	if s.history != "" && len(superseded) > 0 {
+		mig, err := appendHistory(s.history, header, superseded, s.dialect)
+		if err != nil {
+			return trErr("upsert.history", s.history, err)
+		}
+		if mig != nil && s.log != nil {
+			s.log("csv_migration", errors.New(tr("csv.migrated")), mig.String())
+		}
+	}
+	if err := writeCSVFile(s.path, header, kept, s.dialect); err != nil {
+		return trErr("upsert.rewrite", s.path, err)
+	}
+	return nil
+}
+
+// identityKey joins the identity columns; every one must be set to a real
+// value (see placeholderIdentity).
+func identityKey(row, header, identity []string) (string, error) {
+	col := columnIndex(header)
+	parts := make([]string, len(identity))
+	for i, h := range identity {
+		j, ok := col[h]
+		if !ok || j >= len(row) {
+			return "", errNoIdentity
+		}
+		v := strings.ToUpper(strings.TrimSpace(row[j]))
+		if v == "" || placeholderIdentity(v) {
+			return "", errNoIdentity
+		}
+		parts[i] = v
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+// Serials firmware vendors leave in unbranded boards; every such machine
+// would share one row.
+var placeholderSerials = map[string]bool{
+	"TO BE FILLED BY O.E.M.":   true,
+	"TO BE FILLED BY OEM":      true,
+	"DEFAULT STRING":           true,
+	"SYSTEM SERIAL NUMBER":     true,
+	"CHASSIS SERIAL NUMBER":    true,
+	"BASE BOARD SERIAL NUMBER": true,
+	"NOT SPECIFIED":            true,
+	"NOT APPLICABLE":           true,
+	"NONE":                     true,
+	"N/A":                      true,
+	"INVALID":                  true,
+	"OEM":                      true,
+	"0":                        true,
+	"0123456789":               true,
+	"123456789":                true,
+	"1234567890":               true,
+	// AMI's sample UUID, shipped on many boards.
+	"03000200-0400-0500-0006-000700080009": true,
+}
+
+// placeholderIdentity reports values that do not identify a machine: known
+// OEM fillers and all-zero/all-F UUIDs. v is trimmed and upper case.
+func placeholderIdentity(v string) bool {
+	if placeholderSerials[v] {
+		return true
+	}
+	hex := strings.ReplaceAll(v, "-", "")
+	return len(hex) == 32 && (strings.Trim(hex, "0") == "" || strings.Trim(hex, "F") == "")
+}
+
+func columnIndex(header []string) map[string]int {
+	m := make(map[string]int, len(header))
+	for i, h := range header {
+		if _, dup := m[h]; !dup {
+			m[h] = i
+		}
+	}
+	return m
+}
+
+// earliestSeen is the oldest First_Seen (or Data, for rows written before
+// First_Seen existed). The fixed date layout sorts as text.
+func earliestSeen(rows [][]string, col map[string]int) string {
+	first := ""
+	for _, r := range rows {
+		for _, h := range []string{FieldFirstSeen.Header, FieldData.Header} {
+			if j, ok := col[h]; ok && j < len(r) && r[j] != "" {
+				if first == "" || r[j] < first {
+					first = r[j]
+				}
+				break
+			}
+		}
+	}
+	return first
+}
+
+// appendHistory adds rows to the history CSV, mapped onto its own header.
+func appendHistory(path string, header []string, rows [][]string, d csvDialect) (*csvMigration, error) {
+	if err := ensureCSVReady(path, header, d); err != nil {
+		return nil, err
+	}
+	// Same schema upgrade as the inventory, so old history stays aligned.
+	histHeader, mig, err := migrateCSVHeader(path, header, d)
+	if err != nil {
+		return nil, err
+	}
+	mapped := make([][]string, len(rows))
+	for i, r := range rows {
+		mapped[i] = remapRow(r, header, histHeader)
+	}
+	return mig, appendCSVRows(path, mapped, d)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func upsertRun(t *testing.T, s csvSink, sn, host, date string) error {
+	t.Helper()
+	return s.Write(newRecord(Values{FieldSN.Key: sn, FieldHost.Key: host, FieldData.Key: date}, "run-"+date))
+}
+
+// A machine seen again replaces its row in place and keeps First_Seen; the
+// old row goes to the history file.
+func TestUpsertReplacesInPlace(t *testing.T) {
+	dir := t.TempDir()
+	s := csvSink{path: filepath.Join(dir, CsvName), upsert: true, identity: []string{FieldSN.Header}, dialect: defaultDialect, history: filepath.Join(dir, "historico.csv")}
+	for _, r := range [][3]string{
+		{"SN1", "PC-01", "2024-01-02 10:00:00"},
+		{"SN2", "PC-02", "2024-01-03 10:00:00"},
+		{"sn1", "PC-01-NOVO", "2024-02-01 09:00:00"},
+	} {
+		if err := upsertRun(t, s, r[0], r[1], r[2]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header, rows, err := readInventoryCSV(s.path, defaultDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := columnIndex(header)
+	if len(rows) != 2 {
+		t.Fatalf("%d rows", len(rows))
+	}
+	if r := rows[0]; r[col["Host"]] != "PC-01-NOVO" || r[col["First_Seen"]] != "2024-01-02 10:00:00" || r[col["Last_Seen"]] != "2024-02-01 09:00:00" {
+		t.Errorf("replaced row = %q", r)
+	}
+	_, hist, err := readInventoryCSV(s.history, defaultDialect)
+	if err != nil || len(hist) != 1 || hist[0][col["Host"]] != "PC-01" {
+		t.Errorf("history = %q, %v", hist, err)
+	}
+}
+
+// Without an identity the row is still written, and the run is warned.
+func TestUpsertNoIdentity(t *testing.T) {
+	s := csvSink{path: filepath.Join(t.TempDir(), CsvName), upsert: true, identity: []string{FieldSN.Header}, dialect: defaultDialect}
+	var warn sinkWarning
+	for range 2 {
+		if err := upsertRun(t, s, "", "PC-01", "2024-01-02 10:00:00"); !errors.As(err, &warn) {
+			t.Fatalf("err = %v, want a warning", err)
+		}
+	}
+	if _, rows, _ := readInventoryCSV(s.path, defaultDialect); len(rows) != 2 {
+		t.Errorf("%d rows, want 2", len(rows))
+	}
+}
+
+func TestIdentityKeyPlaceholders(t *testing.T) {
+	header := []string{FieldSN.Header, FieldUUID.Header}
+	identity := []string{FieldSN.Header, FieldUUID.Header}
+	for _, row := range [][]string{
+		{"To Be Filled By O.E.M.", "4C4C4544-0058-4B10-8033-B7C04F513933"},
+		{"default string", "4C4C4544-0058-4B10-8033-B7C04F513933"},
+		{" System Serial Number ", "4C4C4544-0058-4B10-8033-B7C04F513933"},
+		{"7XK3Q93", "00000000-0000-0000-0000-000000000000"},
+		{"7XK3Q93", "FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF"},
+		{"7XK3Q93", "03000200-0400-0500-0006-000700080009"},
+		{"7XK3Q93", ""},
+	} {
+		if k, err := identityKey(row, header, identity); err == nil {
+			t.Errorf("%q: key %q", row, k)
+		}
+	}
+	k, err := identityKey([]string{"7xk3q93", "4c4c4544-0058-4b10-8033-b7c04f513933"}, header, identity)
+	if err != nil || k != "7XK3Q93\x004C4C4544-0058-4B10-8033-B7C04F513933" {
+		t.Errorf("real identity: %q, %v", k, err)
+	}
+}
+
+func testCSVSink(dir string) csvSink {
+	cfg := defaultConfig()
+	return csvSink{
+		path:     filepath.Join(dir, CsvName),
+		upsert:   true,
+		identity: []string{FieldSN.Header},
+		history:  filepath.Join(dir, "historico.csv"),
+		lock:     lockOptions{wait: time.Second, stale: time.Minute},
+		dialect:  cfg.csvDialect(),
+		vocab:    cfg.Vocabulary,
+		clean:    cfg.sanitizer(),
+	}
+}
+
+// Two unbranded boards with the same filler serial stay two rows.
+func TestUpsertPlaceholderSerial(t *testing.T) {
+	s := testCSVSink(t.TempDir())
+	var warn sinkWarning
+	for _, host := range []string{"PC-01", "PC-02"} {
+		rec := newRecord(Values{FieldSN.Key: "To Be Filled By O.E.M.", FieldHost.Key: host}, "run-"+host)
+		if err := s.Write(rec); !errors.As(err, &warn) {
+			t.Fatalf("%s: err = %v, want an append warning", host, err)
+		}
+	}
+	_, rows, err := readInventoryCSV(s.path, s.dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("%d rows, want 2", len(rows))
+	}
+}
+
+// A history file from an older build gets the current header before the
+// superseded row is added, instead of the row shifting under old columns.
+func TestUpsertHistoryMigrated(t *testing.T) {
+	dir := t.TempDir()
+	s := testCSVSink(dir)
+	var logged []string
+	s.log = func(ctx string, err error, detail string) { logged = append(logged, ctx) }
+	oldHeader := []string{FieldData.Header, FieldSN.Header, "Obs"}
+	os.WriteFile(s.history, s.dialect.encode(oldHeader, [][]string{{"2024-01-02 10:00:00", "SN1", "old"}}), 0644)
+
+	for _, host := range []string{"PC-01", "PC-01B"} {
+		rec := newRecord(Values{FieldSN.Key: "SN1", FieldHost.Key: host}, "run-"+host)
+		if err := s.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	header, rows, err := readInventoryCSV(s.history, s.dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := columnIndex(header)
+	if _, ok := col["Obs"]; !ok || len(rows) != 2 {
+		t.Fatalf("header %q, %d rows", header, len(rows))
+	}
+	if rows[0][col["Obs"]] != "old" || rows[0][col[FieldSN.Header]] != "SN1" {
+		t.Errorf("old row = %q", rows[0])
+	}
+	if rows[1][col[FieldHost.Header]] != "PC-01" || rows[1][col["Obs"]] != "" {
+		t.Errorf("superseded row = %q", rows[1])
+	}
+	if !slices.Contains(logged, "csv_migration") {
+		t.Errorf("migration not logged: %q", logged)
+	}
+}
+
+// Backups taken in the same second get distinct names.
+func TestWriteBackupSameSecond(t *testing.T) {
+	path := filepath.Join(t.TempDir(), CsvName)
+	seen := map[string]bool{}
+	for i := range 3 {
+		name, err := writeBackup(path, []byte{byte(i)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[name] {
+			t.Fatalf("%s reused", name)
+		}
+		seen[name] = true
+	}
+}
@@ -3,16 +3,22 @@ package main
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 )
 
 // Keep usage simple for field techs.
 func PrintUsageAndExit() {
-	exe := filepath.Base(os.Args[0])
-	fmt.Fprintf(os.Stderr, "usage: .\\%s <patrimonio> <nome> <local>\n", exe)
-	fmt.Fprintln(os.Stderr, "exemplos:")
+	exe := exeName()
+	prefix := tr("usage.prefix")
+	fmt.Fprintf(os.Stderr, "%s .\\%s %s\n", prefix, exe, tr("usage.legacy_args"))
+	fmt.Fprintf(os.Stderr, "%*s .\\%s %s\n", len(prefix), "", exe, tr("usage.command_args"))
+	fmt.Fprintln(os.Stderr, tr("usage.commands"))
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.name, tr(c.summary))
+	}
+	fmt.Fprintln(os.Stderr, tr("usage.examples"))
 	fmt.Fprintf(os.Stderr, "  .\\%s 1029382 laura financeiro\n", exe)
-	fmt.Fprintf(os.Stderr, "  .\\%s 1029382 joao \"andar 4\"\n", exe)
+	fmt.Fprintf(os.Stderr, "  .\\%s collect --asset 1029382 --name joao --location \"andar 4\"\n", exe)
+	fmt.Fprintf(os.Stderr, "  .\\%s --lang en show --format json\n", exe)
 	os.Exit(2)
 }
 
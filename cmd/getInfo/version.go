@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time: go build -ldflags "-X main.version=1.4.0"
+var version = "dev"
+
+func versionString() string {
+	s := fmt.Sprintf("getInfo %s (%s/%s, %s)", version, runtime.GOOS, runtime.GOARCH, runtime.Version())
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, st := range bi.Settings {
+			if st.Key == "vcs.revision" && len(st.Value) >= 7 {
+				s += " rev " + st.Value[:7]
+			}
+		}
+	}
+	return s
+}
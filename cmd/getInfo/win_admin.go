@@ -0,0 +1,15 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+import "strings"
+
+// With manifest we should always be elevated; this is just a safety log.
+func windowsIsAdmin(c *collector) bool {
+	out, err := c.runPS(`[bool]([Security.Principal.WindowsPrincipal][Security.Principal.WindowsIdentity]::GetCurrent()).IsInRole([Security.Principal.WindowsBuiltInRole]::Administrator)`)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(strings.TrimSpace(out), "True") || strings.EqualFold(strings.TrimSpace(out), "true")
+}
@@ -0,0 +1,63 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// One PowerShell run for the three sources. "S" marks that Security Center
+// answered (servers have no root/SecurityCenter2), so "no products" and "not
+// available" can be told apart.
+const securityScript = `$ErrorActionPreference = 'SilentlyContinue'
+$av = Get-CimInstance -Namespace root/SecurityCenter2 -ClassName AntiVirusProduct; if ($?) { 'S' }
+$av | ForEach-Object { 'A',$_.displayName,$_.productState,$_.pathToSignedReportingExe -join '|' }
+Get-MpComputerStatus | ForEach-Object { 'D',$_.AMServiceEnabled,$_.AntivirusEnabled,$_.RealTimeProtectionEnabled,$_.AntivirusSignatureAge,$_.AMRunningMode -join '|' }
+Get-ItemProperty 'HKLM:\SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall\*','HKLM:\SOFTWARE\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\*' | Where-Object { $_.DisplayName -like 'Bitdefender*' } | ForEach-Object { 'B',$_.DisplayName -join '|' }`
+
+func windowsSecurity(c *collector) (*securityInfo, error) {
+	out, err := c.runPS(securityScript)
+	if err != nil {
+		return nil, err
+	}
+	return parseSecurity(out), nil
+}
+
+func parseSecurity(out string) *securityInfo {
+	info := &securityInfo{}
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Split(strings.TrimSpace(ln), "|")
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+		switch {
+		case f[0] == "S" && len(f) == 1:
+			if info.products == nil {
+				info.products = []AVProduct{}
+			}
+		case f[0] == "A" && len(f) == 4:
+			ps, err := strconv.ParseUint(f[2], 10, 32)
+			if err != nil {
+				continue
+			}
+			p := AVProduct{Name: f[1], ProductState: uint32(ps), Path: f[3]}
+			p.State, p.UpToDate = decodeProductState(p.ProductState)
+			info.products = append(info.products, p)
+		case f[0] == "D" && len(f) == 6:
+			age, _ := strconv.Atoi(f[4])
+			d := &DefenderStatus{
+				RealTime:         strings.EqualFold(f[3], "True"),
+				Mode:             f[5],
+				SignatureAgeDays: age,
+				UpToDate:         age <= defenderMaxSignatureAgeDays,
+			}
+			d.State = defenderState(strings.EqualFold(f[1], "True"), strings.EqualFold(f[2], "True"), d.RealTime, d.Mode)
+			info.defender = d
+		case f[0] == "B" && len(f) == 2 && f[1] != "":
+			info.bitdefender = append(info.bitdefender, f[1])
+		}
+	}
+	return info
+}
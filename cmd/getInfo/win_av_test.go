@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestParseSecurity(t *testing.T) {
+	out := "S\r\nA|Windows Defender|397568|windowsdefender://\r\nA|Acme AV|bad|x\r\nD|True|True|True|9|Normal\r\nB|Bitdefender Agent\r\nB|Bitdefender Total Security"
+	info := parseSecurity(out)
+	if len(info.products) != 1 || info.products[0].State != avOn {
+		t.Fatalf("products = %+v", info.products)
+	}
+	if d := info.defender; d == nil || d.State != avOn || d.UpToDate {
+		t.Errorf("defender = %+v", d)
+	}
+	if got := bitdefenderEdition(info.bitdefender, info.products); got != "Bitdefender Total Security" {
+		t.Errorf("edition = %q", got)
+	}
+	// No "S": Security Center did not answer (server), not "no antivirus".
+	if info := parseSecurity("D|True|True|True|0|Normal"); info.products != nil {
+		t.Errorf("products = %+v", info.products)
+	}
+}
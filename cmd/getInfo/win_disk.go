@@ -0,0 +1,206 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+func windowsDiskSystemGiB(c *collector) (total string, free string) {
+	sysDrive := c.getenvOr("SystemDrive", "C:")
+	// PS first (fast & reliable)
+	if out, err := c.runPS(fmt.Sprintf(`$d = Get-CimInstance Win32_LogicalDisk -Filter "DeviceID='%s'"; "$($d.Size)`+"\n"+`$($d.FreeSpace)"`, sysDrive)); err == nil && out != "" {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		if len(lines) >= 2 {
+			if sz, err1 := parseInt64Any(lines[0]); err1 == nil {
+				if fr, err2 := parseInt64Any(lines[1]); err2 == nil {
+					return strconvFormatInt(toGiB(sz)), strconvFormatInt(toGiB(fr))
+				}
+			}
+		}
+	}
+	// Fallback: wmic
+	out2, err2 := c.runCMD(fmt.Sprintf(`wmic logicaldisk where "DeviceID='%s'" get Size,FreeSpace /value`, sysDrive))
+	if err2 == nil {
+		var szB, frB int64
+		for _, ln := range strings.Split(out2, "\n") {
+			t := strings.ToLower(strings.TrimSpace(ln))
+			if strings.HasPrefix(t, "size=") {
+				if v, err := parseInt64Any(ln); err == nil {
+					szB = v
+				}
+			} else if strings.HasPrefix(t, "freespace=") {
+				if v, err := parseInt64Any(ln); err == nil {
+					frB = v
+				}
+			}
+		}
+		if szB > 0 {
+			return strconvFormatInt(toGiB(szB)), strconvFormatInt(toGiB(frB))
+		}
+	}
+	c.addErr("disk", ErrNotFound, "")
+	return "", ""
+}
+
+// Get-PhysicalDisk (Storage module, Windows 8+) has bus and media type;
+// Get-Partition maps drive letters to disk numbers. Fixed and removable
+// volumes only: network drives are not this machine's storage.
+const storageScript = `$ErrorActionPreference = 'SilentlyContinue'
+Get-PhysicalDisk | ForEach-Object { 'P',$_.DeviceId,$_.FriendlyName,$_.SerialNumber,$_.BusType,$_.MediaType,$_.Size -join '|' }
+Get-Partition | Where-Object DriveLetter | ForEach-Object { 'L',$_.DriveLetter,$_.DiskNumber -join '|' }
+Get-CimInstance Win32_LogicalDisk -Filter 'DriveType=2 OR DriveType=3' | ForEach-Object { 'V',$_.DeviceID,$_.VolumeName,$_.FileSystem,$_.Size,$_.FreeSpace -join '|' }`
+
+func windowsReadStorage(c *collector) (*storageInfo, error) {
+	out, err := c.runPS(storageScript)
+	s := &storageInfo{}
+	if err == nil {
+		s = parseWindowsStorage(out)
+	}
+	if len(s.disks) == 0 {
+		// No Storage module (Windows 7, WinPE) or no PowerShell: WMIC has
+		// the disks without media type, which isSSD then takes from the model.
+		if wmicStorage(c, s) {
+			err = nil
+		}
+	}
+	if len(s.disks) == 0 && len(s.volumes) == 0 {
+		return nil, firstNonNil(err, ErrNotFound)
+	}
+	s.markSystem(c.getenvOr("SystemDrive", "C:"))
+	if s.systemDisk() == nil && len(s.disks) == 1 {
+		s.disks[0].System = true
+	}
+	return s, nil
+}
+
+// wmicStorage adds the WMIC view of the disks and of which disk each drive
+// letter is on; it reports whether any disk was found.
+func wmicStorage(c *collector, s *storageInfo) bool {
+	out, err := c.runCMD(`wmic diskdrive get Index,InterfaceType,Model,Size /value`)
+	if err != nil {
+		return false
+	}
+	s.disks = append(s.disks, parseWMICDiskDrives(out)...)
+	if len(s.disks) == 0 {
+		return false
+	}
+	if out, err := c.runCMD(`wmic path Win32_LogicalDiskToPartition get Antecedent,Dependent /value`); err == nil {
+		for letter, disk := range parseWMICDiskLetters(out) {
+			found := false
+			for i := range s.volumes {
+				if s.volumes[i].Mount == letter {
+					s.volumes[i].Disk, found = disk, true
+				}
+			}
+			if !found {
+				s.volumes = append(s.volumes, Volume{Mount: letter, Disk: disk})
+			}
+		}
+		slices.SortFunc(s.volumes, func(a, b Volume) int { return strings.Compare(a.Mount, b.Mount) })
+	}
+	return true
+}
+
+// parseWMICDiskDrives reads "wmic diskdrive ... /value": one Key=Value per
+// line, a blank line between disks.
+func parseWMICDiskDrives(out string) []PhysicalDisk {
+	var disks []PhysicalDisk
+	var d *PhysicalDisk
+	for _, ln := range strings.Split(out, "\n") {
+		k, v, ok := strings.Cut(strings.TrimSpace(ln), "=")
+		if !ok {
+			continue
+		}
+		v = strings.TrimSpace(v)
+		switch strings.ToLower(k) {
+		case "index":
+			disks = append(disks, PhysicalDisk{Name: v})
+			d = &disks[len(disks)-1]
+		case "interfacetype":
+			if d != nil {
+				d.Bus = v
+			}
+		case "model":
+			if d != nil {
+				d.Model = v
+			}
+		case "size":
+			if n, err := parseInt64Any(v); d != nil && err == nil {
+				d.SizeGB = toGiB(n)
+			}
+		}
+	}
+	return disks
+}
+
+var (
+	wmicDiskNumber = regexp.MustCompile(`Disk #(\d+),`)
+	wmicDriveID    = regexp.MustCompile(`DeviceID="([A-Za-z]:)"`)
+)
+
+// parseWMICDiskLetters maps drive letters to disk numbers from
+// Win32_LogicalDiskToPartition (Antecedent = partition, Dependent = volume).
+func parseWMICDiskLetters(out string) map[string]string {
+	m := map[string]string{}
+	disk := ""
+	for _, ln := range strings.Split(out, "\n") {
+		k, v, _ := strings.Cut(strings.TrimSpace(ln), "=")
+		switch strings.ToLower(k) {
+		case "antecedent":
+			disk = ""
+			if sm := wmicDiskNumber.FindStringSubmatch(v); sm != nil {
+				disk = sm[1]
+			}
+		case "dependent":
+			if sm := wmicDriveID.FindStringSubmatch(v); sm != nil && disk != "" {
+				m[strings.ToUpper(sm[1])] = disk
+			}
+		}
+	}
+	return m
+}
+
+func parseWindowsStorage(out string) *storageInfo {
+	s := &storageInfo{}
+	letterDisk := map[string]string{}
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Split(strings.TrimSpace(ln), "|")
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+		switch {
+		case f[0] == "P" && len(f) == 7:
+			d := PhysicalDisk{Name: f[1], Model: f[2], Serial: f[3], Bus: f[4]}
+			switch {
+			case strings.EqualFold(f[5], "SSD"), strings.EqualFold(f[5], "SCM"):
+				d.Media = mediaSSD
+			case strings.EqualFold(f[5], "HDD"):
+				d.Media = mediaHDD
+			}
+			if v, err := parseInt64Any(f[6]); err == nil {
+				d.SizeGB = toGiB(v)
+			}
+			s.disks = append(s.disks, d)
+		case f[0] == "L" && len(f) == 3:
+			letterDisk[strings.ToUpper(f[1])+":"] = f[2]
+		case f[0] == "V" && len(f) == 6:
+			v := Volume{Mount: strings.ToUpper(f[1]), Label: f[2], FS: f[3]}
+			if n, err := parseInt64Any(f[4]); err == nil {
+				v.SizeGB = toGiB(n)
+			}
+			if n, err := parseInt64Any(f[5]); err == nil {
+				v.FreeGB = toGiB(n)
+			}
+			s.volumes = append(s.volumes, v)
+		}
+	}
+	for i := range s.volumes {
+		s.volumes[i].Disk = letterDisk[s.volumes[i].Mount]
+	}
+	return s
+}
@@ -0,0 +1,70 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Win32_VideoController lists the present adapters, but AdapterRAM is a
+// uint32 and caps at 4 GB. The display class key has the 64-bit
+// HardwareInformation.qwMemorySize the driver reports (older drivers only
+// write the 32-bit MemorySize, sometimes as REG_BINARY).
+const gpuScript = `Get-CimInstance Win32_VideoController | ForEach-Object { 'V',$_.Name,$_.AdapterCompatibility,$_.DriverVersion,$(if ($_.DriverDate) { $_.DriverDate.ToString('yyyy-MM-dd') }),$_.AdapterRAM,$_.PNPDeviceID -join '|' }
+Get-ItemProperty 'HKLM:\SYSTEM\CurrentControlSet\Control\Class\{4d36e968-e325-11ce-bfc1-08002be10318}\0*' -ErrorAction SilentlyContinue | ForEach-Object { $m = $_.'HardwareInformation.MemorySize'; if ($m -is [byte[]]) { $m = [BitConverter]::ToUInt32($m, 0) }; 'R',$_.DriverDesc,$_.'HardwareInformation.qwMemorySize',$m -join '|' }`
+
+func windowsGPUs(c *collector) ([]GPUAdapter, error) {
+	out, err := c.runPS(gpuScript)
+	if err != nil {
+		return nil, err
+	}
+	return parseWindowsGPUs(out), nil
+}
+
+// parseWindowsGPUs joins the controller lines (V) with the driver registry
+// entries (R) by name. The class key also keeps entries of removed adapters,
+// so it never adds GPUs on its own.
+func parseWindowsGPUs(out string) []GPUAdapter {
+	var gpus []GPUAdapter
+	regVRAM := map[string]int64{}
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Split(strings.TrimSpace(ln), "|")
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+		switch {
+		case f[0] == "V" && len(f) == 7:
+			g := GPUAdapter{Name: f[1], Vendor: f[2], DriverVersion: f[3], DriverDate: f[4], PCIID: pnpPCIID(f[6])}
+			if v, err := strconv.ParseInt(f[5], 10, 64); err == nil && v > 0 {
+				g.VRAMMB = v / (1024 * 1024)
+			}
+			gpus = append(gpus, g)
+		case f[0] == "R" && len(f) == 4:
+			for _, s := range f[2:] {
+				if v, err := strconv.ParseInt(s, 10, 64); err == nil && v > 0 {
+					regVRAM[f[1]] = max(regVRAM[f[1]], v/(1024*1024))
+					break
+				}
+			}
+		}
+	}
+	for i := range gpus {
+		if v := regVRAM[gpus[i].Name]; v > 0 {
+			gpus[i].VRAMMB = v
+		}
+	}
+	return gpus
+}
+
+// pnpPCIID turns PCI\VEN_10DE&DEV_1F82&... into "10de:1f82".
+func pnpPCIID(pnp string) string {
+	up := strings.ToUpper(pnp)
+	_, ven, ok1 := strings.Cut(up, "VEN_")
+	_, dev, ok2 := strings.Cut(up, "DEV_")
+	if !ok1 || !ok2 || len(ven) < 4 || len(dev) < 4 {
+		return ""
+	}
+	return strings.ToLower(ven[:4] + ":" + dev[:4])
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+// AdapterRAM caps at 4 GB; the driver's 64-bit size wins. Registry entries of
+// removed adapters add nothing.
+func TestParseWindowsGPUs(t *testing.T) {
+	gpus := parseWindowsGPUs("V|NVIDIA GeForce RTX 3070|NVIDIA|31.0.15.3623|2023-08-02|4293918720|PCI\\VEN_10DE&DEV_2484&SUBSYS_146B10DE&REV_A1\\4&1A2B3C4D&0&0008\r\n" +
+		"V|Intel(R) UHD Graphics 630|Intel Corporation|27.20.100.9316||1073741824|PCI\\VEN_8086&DEV_3E92&SUBSYS_085A1028&REV_00\\3&11583659&0&10\r\n" +
+		"R|NVIDIA GeForce RTX 3070|8589934592|4293918720\r\n" +
+		"R|NVIDIA GeForce GTX 1050||2147483648\r\n")
+	if len(gpus) != 2 {
+		t.Fatalf("gpus = %+v", gpus)
+	}
+	want := GPUAdapter{Name: "NVIDIA GeForce RTX 3070", Vendor: "NVIDIA", PCIID: "10de:2484", DriverVersion: "31.0.15.3623", DriverDate: "2023-08-02", VRAMMB: 8192}
+	if gpus[0] != want {
+		t.Errorf("gpu 0 = %+v", gpus[0])
+	}
+	if gpus[1].VRAMMB != 1024 || gpus[1].PCIID != "8086:3e92" || gpus[1].driverLabel() != "27.20.100.9316" {
+		t.Errorf("gpu 1 = %+v", gpus[1])
+	}
+}
@@ -0,0 +1,187 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+func windowsSerial(c *collector) string {
+	if info := c.smbios(); info != nil && info.Serial != "" {
+		return info.Serial
+	}
+	if out, err := c.runPS(`(Get-CimInstance -ClassName Win32_BIOS).SerialNumber`); err == nil && strings.TrimSpace(out) != "" {
+		return firstLine(out)
+	}
+	out2, err2 := c.runCMD(`wmic bios get serialnumber /value`)
+	if err2 == nil {
+		for _, ln := range strings.Split(out2, "\n") {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(ln)), "serialnumber=") {
+				return strings.TrimSpace(strings.SplitN(ln, "=", 2)[1])
+			}
+		}
+	}
+	c.addErr("serial", ErrNotFound, "")
+	return ""
+}
+
+func windowsUUID(c *collector) string {
+	if info := c.smbios(); info != nil && info.UUID != "" {
+		return info.UUID
+	}
+	if out, err := c.runPS(`(Get-CimInstance Win32_ComputerSystemProduct).UUID`); err == nil && strings.TrimSpace(out) != "" {
+		return firstLine(out)
+	}
+	out2, err2 := c.runCMD(`wmic csproduct get UUID /value`)
+	if err2 == nil && strings.TrimSpace(out2) != "" {
+		for _, ln := range strings.Split(out2, "\n") {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(ln)), "uuid=") {
+				return strings.TrimSpace(strings.SplitN(ln, "=", 2)[1])
+			}
+		}
+	}
+	c.addErr("uuid_smbios", ErrNotFound, "")
+	return ""
+}
+
+func windowsCPU(c *collector) string {
+	if out, err := c.runPS(`(Get-CimInstance Win32_Processor | Select-Object -First 1 -ExpandProperty Name)`); err == nil && strings.TrimSpace(out) != "" {
+		return firstLine(out)
+	}
+	out2, err2 := c.runCMD(`wmic cpu get Name /value`)
+	if err2 == nil && out2 != "" {
+		for _, ln := range strings.Split(out2, "\n") {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(ln)), "name=") {
+				return strings.TrimSpace(strings.SplitN(ln, "=", 2)[1])
+			}
+		}
+	}
+	c.addErr("cpu", ErrNotFound, runtime.GOARCH)
+	return ""
+}
+
+func windowsTotalRAMGiB(c *collector) string {
+	if out, err := c.runPS(`(Get-CimInstance Win32_ComputerSystem).TotalPhysicalMemory`); err == nil && out != "" {
+		if v, err := parseInt64Any(out); err == nil {
+			return ToStr(toGiB(v))
+		}
+	}
+	out2, err2 := c.runCMD(`wmic os get TotalVisibleMemorySize /value`)
+	if err2 == nil {
+		for _, ln := range strings.Split(out2, "\n") {
+			if strings.HasPrefix(strings.ToLower(strings.TrimSpace(ln)), "totalvisiblememorysize=") {
+				if vKB, err := parseInt64Any(ln); err == nil {
+					return ToStr(toGiB(vKB * 1024))
+				}
+			}
+		}
+	}
+	c.addErr("ram_total", ErrNotFound, "")
+	return ""
+}
+
+// windowsRAMModules falls back to Win32_PhysicalMemory, which only lists
+// populated slots and has no form factor in SMBIOS terms.
+func windowsRAMModules(c *collector) []MemoryModule {
+	if info := c.smbios(); info != nil {
+		if mods := info.memoryModules(); len(mods) > 0 {
+			return mods
+		}
+	}
+	out, err := c.runPS(`Get-CimInstance Win32_PhysicalMemory | ForEach-Object { $_.DeviceLocator,$_.BankLabel,$_.Capacity,$_.SMBIOSMemoryType,$_.Speed,$_.ConfiguredClockSpeed,$_.Manufacturer,$_.PartNumber,$_.SerialNumber -join '|' }`)
+	if err != nil {
+		return nil
+	}
+	return parseWin32Memory(out)
+}
+
+// parseWin32Memory reads the "|"-joined lines of windowsRAMModules.
+func parseWin32Memory(out string) []MemoryModule {
+	var mods []MemoryModule
+	for _, ln := range strings.Split(out, "\n") {
+		f := strings.Split(strings.TrimSpace(ln), "|")
+		if len(f) != 9 {
+			continue
+		}
+		for i := range f {
+			f[i] = strings.TrimSpace(f[i])
+		}
+		capacity, _ := strconv.ParseInt(f[2], 10, 64)
+		typ, _ := strconv.ParseUint(f[3], 10, 8)
+		speed, _ := strconv.Atoi(f[4])
+		configured, _ := strconv.Atoi(f[5])
+		mods = append(mods, MemoryModule{
+			Slot:          f[0],
+			Bank:          f[1],
+			SizeMB:        capacity / (1024 * 1024),
+			Type:          memoryTypeName(uint8(typ)),
+			SpeedMTs:      speed,
+			ConfiguredMTs: configured,
+			Manufacturer:  f[6],
+			PartNumber:    f[7],
+			Serial:        f[8],
+		})
+	}
+	return mods
+}
+
+func windowsRAMSlots(c *collector) (used int64, total int64, okUsed bool, okTotal bool) {
+	if info := c.smbios(); info != nil {
+		if used, total, ok := info.memorySlots(); ok {
+			return used, total, true, true
+		}
+	}
+	// Used
+	if out, err := c.runPS(`(Get-CimInstance Win32_PhysicalMemory | Measure-Object).Count`); err == nil && strings.TrimSpace(out) != "" {
+		if v, err := parseInt64Any(out); err == nil {
+			used, okUsed = v, true
+		}
+	}
+	// Total
+	if out, err := c.runPS(`(Get-CimInstance Win32_PhysicalMemoryArray | Select-Object -ExpandProperty MemoryDevices)`); err == nil && strings.TrimSpace(out) != "" {
+		var sum int64
+		for _, ln := range strings.Split(strings.TrimSpace(out), "\n") {
+			if v, err := parseInt64Any(ln); err == nil {
+				sum += v
+			}
+		}
+		if sum > 0 {
+			total, okTotal = sum, true
+		}
+	}
+	// Fallbacks
+	if !okUsed {
+		if out, err := c.runCMD(`wmic memorychip get banklabel`); err == nil && strings.TrimSpace(out) != "" {
+			cnt := 0
+			for _, ln := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+				if strings.TrimSpace(ln) != "" {
+					cnt++
+				}
+			}
+			if cnt > 0 {
+				used, okUsed = int64(cnt), true
+			}
+		}
+	}
+	if !okTotal {
+		if out, err := c.runCMD(`wmic memphysical get memorydevices /value`); err == nil {
+			for _, ln := range strings.Split(out, "\n") {
+				if strings.HasPrefix(strings.ToLower(strings.TrimSpace(ln)), "memorydevices=") {
+					if v, err := parseInt64Any(ln); err == nil && v > 0 {
+						total, okTotal = v, true
+					}
+				}
+			}
+		}
+	}
+	if !okUsed {
+		c.addErr("ram_slots_used", ErrNotFound, "")
+	}
+	if !okTotal {
+		c.addErr("ram_slots_total", ErrNotFound, "")
+	}
+	return
+}
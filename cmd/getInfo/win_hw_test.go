@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// A Windows collector runs unchanged against a recording: PowerShell fails
+// with an exit code and the WMIC fallback supplies the value.
+func TestReplaySerialFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	os.WriteFile(path, []byte(`[
+  {"name": "powershell", "args": ["-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass", "-Command", "(Get-CimInstance -ClassName Win32_BIOS).SerialNumber"],
+   "output": "Get-CimInstance : Invalid class", "err": "exit status 1", "exitCode": 1},
+  {"name": "cmd", "args": ["/C", "wmic bios get serialnumber /value"], "output": "SerialNumber=7XK3Q93", "exitCode": 0}
+]`), 0644)
+	rr, err := loadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCollector()
+	c.runner, c.plat = rr, windowsPlatform
+	if got := getSerial(c); got != "7XK3Q93" || len(c.errors()) != 0 {
+		t.Errorf("serial = %q, errors %q", got, c.errors())
+	}
+}
+
+// Without an SMBIOS table the modules come from Win32_PhysicalMemory;
+// SMBIOSMemoryType maps to the type name and 0 (older WMI) stays unknown.
+func TestReplayRAMModules(t *testing.T) {
+	rr, err := loadFixtures(filepath.Join("testdata", "ram_modules_windows.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newCollector()
+	c.runner, c.plat = rr, windowsPlatform
+	mods := getRAMModules(c)
+	if len(mods) != 3 {
+		t.Fatalf("modules = %+v", mods)
+	}
+	want := MemoryModule{Slot: "DIMM1", Bank: "BANK 0", SizeMB: 16384, Type: "DDR4", SpeedMTs: 3200, ConfiguredMTs: 2933, Manufacturer: "Samsung", PartNumber: "M378A2K43DB1-CTD", Serial: "1A2B3C4D"}
+	if mods[0] != want {
+		t.Errorf("module 0 = %+v", mods[0])
+	}
+	if mods[2].Type != "" || mods[2].SizeMB != 8192 || mods[2].Serial != "" {
+		t.Errorf("module 2 = %+v", mods[2])
+	}
+	if s := memorySummary(mods); s != "DDR4 2x16GB+1x8GB 2933" {
+		t.Errorf("summary = %q", s)
+	}
+	for typ, name := range map[string]string{"24": "DDR3", "26": "DDR4", "34": "DDR5", "35": "LPDDR5", "2": ""} {
+		mods := parseWin32Memory("DIMM0|BANK 0|8589934592|" + typ + "|4800|4800|Micron|MTC8C1084S1SC48BA1|")
+		if len(mods) != 1 || mods[0].Type != name {
+			t.Errorf("type %s = %+v, want %q", typ, mods, name)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+// The adapter with default IPv4 gateway and status Up.
+func windowsGatewayIPv4(c *collector) string {
+	ps := `(Get-NetIPConfiguration | ? { $_.IPv4DefaultGateway -ne $null -and $_.NetAdapter.Status -eq 'Up' } | select -First 1).IPv4Address.IPAddress`
+	if out, err := c.runPS(ps); err == nil {
+		return firstLine(out)
+	}
+	return ""
+}
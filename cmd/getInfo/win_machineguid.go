@@ -1,11 +1,14 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
 package main
 
 import "strings"
 
 // MachineGuid is unique per Windows installation (can change after sysprep).
-func getMachineGuid(c *collector) string {
+func windowsMachineGuid(c *collector) string {
 	// Fast path: reg.exe query
-	out, err := runCMD(`reg query "HKLM\SOFTWARE\Microsoft\Cryptography" /v MachineGuid`)
+	out, err := c.runCMD(`reg query "HKLM\SOFTWARE\Microsoft\Cryptography" /v MachineGuid`)
 	if err == nil && strings.TrimSpace(out) != "" {
 		for _, ln := range strings.Split(out, "\n") {
 			if strings.Contains(ln, "MachineGuid") {
@@ -18,7 +21,7 @@ func getMachineGuid(c *collector) string {
 	}
 	// Fallback: PowerShell
 	ps := `(Get-ItemProperty 'HKLM:\SOFTWARE\Microsoft\Cryptography').MachineGuid`
-	if o2, e2 := runPS(ps); e2 == nil && strings.TrimSpace(o2) != "" {
+	if o2, e2 := c.runPS(ps); e2 == nil && strings.TrimSpace(o2) != "" {
 		return firstLine(o2)
 	}
 	c.addErr("machineguid", err, "")
@@ -0,0 +1,46 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
+package main
+
+import "strings"
+
+func windowsCurrentUser(c *collector) string {
+	out, err := c.runCMD("whoami")
+	if err == nil && out != "" {
+		return strings.TrimSpace(out)
+	}
+	u := c.getenv("USERNAME")
+	if u == "" {
+		c.addErr("user", err, tr("env.empty", "USERNAME"))
+	}
+	return u
+}
+
+func windowsOSVersion(c *collector) string {
+	out, err := c.runCMD(`reg query "HKLM\SOFTWARE\Microsoft\Windows NT\CurrentVersion" /v ProductName`)
+	if err == nil && out != "" {
+		for _, ln := range strings.Split(out, "\n") {
+			if strings.Contains(ln, "ProductName") {
+				idx := strings.Index(ln, "REG_SZ")
+				if idx >= 0 {
+					product := strings.TrimSpace(ln[idx+len("REG_SZ"):])
+					switch {
+					case strings.Contains(product, "Windows 11"):
+						return "11"
+					case strings.Contains(product, "Windows 10"):
+						return "10"
+					}
+				}
+			}
+		}
+	}
+	out2, _ := c.runCMD("ver")
+	if strings.Contains(out2, "Version 10.") {
+		return "10"
+	}
+	if strings.Contains(out2, "Version 11.") {
+		return "11"
+	}
+	return ""
+}
@@ -1,3 +1,6 @@
+// Windows queries over the Runner. No build tag: this file compiles on every
+// OS so Windows recordings replay anywhere (see platform.go).
+
 package main
 
 import (
@@ -5,8 +8,8 @@ import (
 	"strings"
 )
 
-func getRDPUser(c *collector) string {
-	out, err := runCMD("query user")
+func windowsRDPUser(c *collector) string {
+	out, err := c.runCMD("query user")
 	if err == nil && out != "" {
 		lines := strings.Split(out, "\n")
 		for _, ln := range lines[1:] {
@@ -23,7 +26,7 @@ func getRDPUser(c *collector) string {
 			}
 		}
 	}
-	out2, err2 := runCMD("query session")
+	out2, err2 := c.runCMD("query session")
 	if err2 == nil && out2 != "" {
 		sc := bufio.NewScanner(strings.NewReader(out2))
 		for sc.Scan() {
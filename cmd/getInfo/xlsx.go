@@ -0,0 +1,430 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Native .xlsx output (stdlib only, so the single-exe deployment stays).
+// Each run reads the workbook back, appends one row to "Inventario" and this
+// run's errors to "Erros", and rewrites the whole file.
+
+const (
+	xlsxSheetInv  = "Inventario"
+	xlsxSheetErrs = "Erros"
+)
+
+// xlsxErrHeader is the Erros sheet's header, in the run's language; the
+// sheet is for people, nothing reads it back by column name, so every write
+// replaces the header the file was created with.
+func xlsxErrHeader() []string {
+	return []string{tr("xlsx.err_date"), tr("xlsx.err_run"), tr("xlsx.err_host"), tr("xlsx.err_error")}
+}
+
+type xlsxSink struct {
+	path  string
+	errs  func() []string // this run's error log lines
+	lock  lockOptions
+	vocab VocabularyConfig
+	clean sanitizer
+}
+
+func (s xlsxSink) Name() string { return "xlsx" }
+
+func (s xlsxSink) Write(rec *InventoryRecord) error {
+	l, err := acquireLock(s.path, s.lock.wait, s.lock.stale)
+	if err != nil {
+		return err
+	}
+	defer l.release()
+
+	inv, errRows, err := readXLSX(s.path)
+	if err != nil {
+		return trErr("file.read", s.path, err)
+	}
+
+	header := Headers()
+	if len(inv) > 0 && !sameHeader(inv[0], header) {
+		// Keep old rows readable when columns change: map them by name.
+		merged := mergeHeader(inv[0], header)
+		rows := [][]string{merged}
+		for _, r := range inv[1:] {
+			rows = append(rows, remapRow(r, inv[0], merged))
+		}
+		inv, header = rows, merged
+	}
+	if len(inv) == 0 {
+		inv = [][]string{header}
+	}
+	inv = append(inv, remapRow(Row(spreadsheetValues(rec, s.clean, s.vocab)), Headers(), header))
+
+	if len(errRows) == 0 {
+		errRows = [][]string{nil}
+	}
+	errRows[0] = xlsxErrHeader()
+	for _, ln := range s.errs() {
+		ts, msg, _ := strings.Cut(ln, " ")
+		errRows = append(errRows, []string{ts, rec.RunID, rec.Host, msg})
+	}
+
+	numeric := map[string]bool{}
+	for _, f := range numericFields {
+		numeric[f.Header] = true
+	}
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, []xlsxSheet{
+		{name: xlsxSheetInv, rows: inv, numeric: numeric},
+		{name: xlsxSheetErrs, rows: errRows},
+	}); err != nil {
+		return err
+	}
+	// Write aside and rename: a crash never leaves a truncated workbook, and a
+	// file open in Excel fails here instead of being half-written.
+	if err := l.check(); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// --- writing ---
+
+type xlsxSheet struct {
+	name    string
+	rows    [][]string      // rows[0] is the header
+	numeric map[string]bool // header -> write numbers as numeric cells
+}
+
+func writeXLSX(w io.Writer, sheets []xlsxSheet) error {
+	zw := zip.NewWriter(w)
+	add := func(name, body string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, xml.Header+body)
+		return err
+	}
+
+	var ct, wbSheets, wbRels, defNames strings.Builder
+	ct.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i, sh := range sheets {
+		n := i + 1
+		fmt.Fprintf(&ct, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&wbSheets, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEsc(sh.name), n, n)
+		fmt.Fprintf(&wbRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, n, n)
+		if ref := filterRef(sh.rows); ref != "" {
+			fmt.Fprintf(&defNames, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">'%s'!%s</definedName>`, i, xmlEsc(sh.name), absRef(ref))
+		}
+		if err := add(fmt.Sprintf("xl/worksheets/sheet%d.xml", n), sheetXML(sh)); err != nil {
+			return err
+		}
+	}
+	ct.WriteString(`</Types>`)
+	fmt.Fprintf(&wbRels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(sheets)+1)
+
+	wb := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets>` + wbSheets.String() + `</sheets>`
+	if defNames.Len() > 0 {
+		wb += `<definedNames>` + defNames.String() + `</definedNames>`
+	}
+	wb += `</workbook>`
+
+	parts := []struct{ name, body string }{
+		{"[Content_Types].xml", ct.String()},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", wb},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + wbRels.String() + `</Relationships>`},
+		// Style 0 = default, 1 = bold (header row).
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for _, p := range parts {
+		if err := add(p.name, p.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func sheetXML(sh xlsxSheet) string {
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	// Frozen header row.
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	if widths := columnWidths(sh.rows); len(widths) > 0 {
+		b.WriteString(`<cols>`)
+		for i, w := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, w)
+		}
+		b.WriteString(`</cols>`)
+	}
+	b.WriteString(`<sheetData>`)
+	var header []string
+	if len(sh.rows) > 0 {
+		header = sh.rows[0]
+	}
+	for ri, row := range sh.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, ri+1)
+		for ci, v := range row {
+			if v == "" {
+				continue
+			}
+			ref := colName(ci) + strconv.Itoa(ri+1)
+			switch {
+			case ri == 0:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr" s="1"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEsc(v))
+			case ci < len(header) && sh.numeric[header[ci]] && isNumber(v):
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, v)
+			default:
+				fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, xmlEsc(v))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData>`)
+	if ref := filterRef(sh.rows); ref != "" {
+		fmt.Fprintf(&b, `<autoFilter ref="%s"/>`, ref)
+	}
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+func filterRef(rows [][]string) string {
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return ""
+	}
+	return "A1:" + colName(len(rows[0])-1) + strconv.Itoa(len(rows))
+}
+
+// "A1:U9" -> "$A$1:$U$9" for the defined name.
+func absRef(ref string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(ref, ":") {
+		if b.Len() > 0 {
+			b.WriteByte(':')
+		}
+		i := strings.IndexAny(part, "0123456789")
+		b.WriteString("$" + part[:i] + "$" + part[i:])
+	}
+	return b.String()
+}
+
+// Width from the longest value per column, clamped to something readable.
+func columnWidths(rows [][]string) []int {
+	var w []int
+	for _, r := range rows {
+		for i, v := range r {
+			for len(w) <= i {
+				w = append(w, 8)
+			}
+			w[i] = max(w[i], utf8.RuneCountInString(v)+2)
+		}
+	}
+	for i := range w {
+		w[i] = min(w[i], 60)
+	}
+	return w
+}
+
+func colName(i int) string {
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
+
+// colIndex reads the column of a cell reference ("B7", "$AB$12"); -1 when
+// there is none.
+func colIndex(ref string) int {
+	n := 0
+	for _, r := range strings.TrimPrefix(strings.ToUpper(ref), "$") {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		n = n*26 + int(r-'A'+1)
+	}
+	return n - 1
+}
+
+// isNumber: only plain decimals become numeric cells; "NaN" or "1e3" would
+// make an invalid or surprising cell.
+func isNumber(s string) bool { return plainNumber.MatchString(s) }
+
+func xmlEsc(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// --- reading (our own files, and the same files after Excel re-saved them) ---
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		RID  string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRels struct {
+	Rels []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSST struct {
+	SI []struct {
+		T string `xml:"t"`
+		R []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"` // 1-based; Excel leaves out empty rows
+		Cells []struct {
+			R  string `xml:"r,attr"`
+			T  string `xml:"t,attr"`
+			V  string `xml:"v"`
+			IS *struct {
+				T string `xml:"t"`
+				R []struct {
+					T string `xml:"t"`
+				} `xml:"r"`
+			} `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readXLSX returns the Inventario and Erros rows; a missing file is empty.
+func readXLSX(p string) (inv, errs [][]string, err error) {
+	zr, err := zip.OpenReader(p)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer zr.Close()
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	load := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return os.ErrNotExist
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return xml.NewDecoder(rc).Decode(v)
+	}
+
+	var wb xlsxWorkbook
+	var rels xlsxRels
+	if err := load("xl/workbook.xml", &wb); err != nil {
+		return nil, nil, err
+	}
+	if err := load("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, nil, err
+	}
+	var sst xlsxSST
+	_ = load("xl/sharedStrings.xml", &sst) // only present after Excel saved it
+	shared := make([]string, len(sst.SI))
+	for i, si := range sst.SI {
+		shared[i] = si.T
+		for _, r := range si.R {
+			shared[i] += r.T
+		}
+	}
+
+	targets := map[string]string{}
+	for _, r := range rels.Rels {
+		t := strings.TrimPrefix(r.Target, "/")
+		if !strings.HasPrefix(t, "xl/") {
+			t = path.Join("xl", t)
+		}
+		targets[r.ID] = t
+	}
+	for _, sh := range wb.Sheets {
+		var dst *[][]string
+		switch sh.Name {
+		case xlsxSheetInv:
+			dst = &inv
+		case xlsxSheetErrs:
+			dst = &errs
+		default:
+			continue
+		}
+		var ws xlsxWorksheet
+		if err := load(targets[sh.RID], &ws); err != nil {
+			return nil, nil, trErr("xlsx.sheet", sh.Name, err)
+		}
+		for _, row := range ws.Rows {
+			// Keep row numbers: a skipped empty row stays an empty row.
+			for row.R > len(*dst)+1 {
+				*dst = append(*dst, nil)
+			}
+			var out []string
+			for _, c := range row.Cells {
+				i := colIndex(c.R)
+				if i < 0 {
+					i = len(out)
+				}
+				for len(out) <= i {
+					out = append(out, "")
+				}
+				switch c.T {
+				case "s":
+					if n, err := strconv.Atoi(c.V); err == nil && n < len(shared) {
+						out[i] = shared[n]
+					}
+				case "inlineStr":
+					if c.IS != nil {
+						out[i] = c.IS.T
+						for _, r := range c.IS.R {
+							out[i] += r.T
+						}
+					}
+				default:
+					out[i] = c.V
+				}
+			}
+			*dst = append(*dst, out)
+		}
+	}
+	return inv, errs, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Each run adds one Inventario row (cleaned) and its error lines to Erros; the rows
+// of earlier runs come back unchanged.
+func TestXLSXSinkAppends(t *testing.T) {
+	s := xlsxSink{path: filepath.Join(t.TempDir(), "inventario.xlsx")}
+	for i, host := range []string{"PC-01", "PC-02"} {
+		s.errs = func() []string {
+			return []string{"2024-03-05T14:07:09-0300 serial: nao encontrado"}[:i]
+		}
+		rec := newRecord(Values{FieldHost.Key: host, FieldRAM.Key: "16", FieldSN.Key: "=1+1"}, "run-"+host)
+		if err := s.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	inv, errs, err := readXLSX(s.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inv) != 3 || !slices.Equal(inv[0], Headers()) {
+		t.Fatalf("inv = %q", inv)
+	}
+	col := map[string]int{}
+	for i, h := range inv[0] {
+		col[h] = i
+	}
+	for i, host := range []string{"PC-01", "PC-02"} {
+		row := inv[i+1]
+		if row[col[FieldHost.Header]] != host || row[col[FieldRAM.Header]] != "16" || row[col[FieldSN.Header]] != "'=1+1" {
+			t.Errorf("row %d = %q", i+1, row)
+		}
+	}
+	want := [][]string{xlsxErrHeader(), {"2024-03-05T14:07:09-0300", "run-PC-02", "PC-02", "serial: nao encontrado"}}
+	if !slices.EqualFunc(errs, want, slices.Equal) {
+		t.Errorf("errs = %q", errs)
+	}
+}
+
+// Numeric columns become number cells only when the value is a number; text
+// is escaped.
+func TestSheetXML(t *testing.T) {
+	xml := sheetXML(xlsxSheet{
+		rows:    [][]string{{"RAM_GB", "Host"}, {"16", "<PC>"}, {"n/d", ""}},
+		numeric: map[string]bool{"RAM_GB": true},
+	})
+	for _, want := range []string{
+		`<c r="A2"><v>16</v></c>`,
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">&lt;PC&gt;</t></is></c>`,
+		`<c r="A3" t="inlineStr">`,
+		`<autoFilter ref="A1:B3"/>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+	if strings.Contains(xml, `r="B3"`) {
+		t.Error("empty cell written")
+	}
+}
+
+func TestColName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"} {
+		if got := colName(i); got != want || colIndex(want+"7") != i {
+			t.Errorf("colName(%d) = %q, colIndex = %d", i, got, colIndex(want+"7"))
+		}
+	}
+}
+
+// writeTestXLSX zips the given parts (name -> XML) into a workbook.
+func writeTestXLSX(t *testing.T, parts map[string]string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "inv.xlsx")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, body := range parts {
+		w, _ := zw.Create(name)
+		w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return p
+}
+
+// Excel leaves out empty rows and cells; their r attributes place the rest.
+func TestReadXLSXSparse(t *testing.T) {
+	p := writeTestXLSX(t, map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Inventario" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>SN</t></si><si><t>Host</t></si><si><r><t>PC-</t></r><r><t>01</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>RAM_GB</t></is></c></row>` +
+			`<row r="3"><c r="B3" t="s"><v>2</v></c><c r="C3"><v>16</v></c></row>` +
+			`</sheetData></worksheet>`,
+	})
+	inv, errs, err := readXLSX(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"SN", "Host", "RAM_GB"}, nil, {"", "PC-01", "16"}}
+	if !slices.EqualFunc(inv, want, slices.Equal) || errs != nil {
+		t.Errorf("inv = %q, errs = %q", inv, errs)
+	}
+}
+
+// The Erros header follows the language of the latest run, not the one
+// that created the workbook.
+func TestXLSXErrHeaderLanguage(t *testing.T) {
+	defer setLanguage(langPT)
+	p := filepath.Join(t.TempDir(), "inventario.xlsx")
+	for i, lang := range []string{langPT, langEN, langES} {
+		setLanguage(lang)
+		s := xlsxSink{path: p, errs: func() []string { return []string{"2024-03-05T14:07:09-0300 serial: x"} }}
+		if err := s.Write(&InventoryRecord{RunID: "run-" + lang}); err != nil {
+			t.Fatal(err)
+		}
+		_, errs, err := readXLSX(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(errs) != i+2 || !slices.Equal(errs[0], xlsxErrHeader()) {
+			t.Errorf("%s: %q", lang, errs)
+		}
+	}
+}